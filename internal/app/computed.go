@@ -0,0 +1,278 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vex/internal/sql"
+	"github.com/vex/internal/theme"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+// maxComputedDepth bounds how deeply computed columns may refer to other
+// computed columns, which also stops circular references
+const maxComputedDepth = 16
+
+// computedError is shown in place of a value that cannot be computed
+const computedError = "#VALUE!"
+
+// computedEdit inserts a computed column right of the cursor column
+type computedEdit struct {
+	structEdit
+	column models.ComputedColumn
+}
+
+func (c *computedEdit) apply(m *Model) {
+	c.structEdit.apply(m)
+	sheet := &m.sheets[c.sheet]
+	sheet.EnsureCell(c.column.Header, c.column.Col).Value = c.column.Name
+	sheet.Computed = append(sheet.Computed, c.column)
+}
+
+func (c *computedEdit) revert(m *Model) {
+	sheet := &m.sheets[c.sheet]
+	kept := make([]models.ComputedColumn, 0, len(sheet.Computed))
+	for _, cc := range sheet.Computed {
+		if cc.Col != c.column.Col {
+			kept = append(kept, cc)
+		}
+	}
+	sheet.Computed = kept
+	c.structEdit.revert(m)
+}
+
+// startComputed opens the prompt for a new computed column
+func (m Model) startComputed() (tea.Model, tea.Cmd) {
+	m.mode = models.ModeComputed
+	m.resetPromptHistory()
+	m.exprInput.Width = max(20, m.width-48)
+	m.exprInput.SetValue("")
+	m.exprInput.Focus()
+	return m, textinput.Blink
+}
+
+// updateComputed handles the computed column prompt
+func (m Model) updateComputed(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.Type {
+	case tea.KeyEscape:
+		m.mode = models.ModeNormal
+		m.exprInput.Blur()
+		return m, nil
+
+	case tea.KeyUp:
+		m.browsePromptHistory(&m.exprInput, "computed", -1)
+		return m, nil
+
+	case tea.KeyDown:
+		m.browsePromptHistory(&m.exprInput, "computed", 1)
+		return m, nil
+
+	case tea.KeyEnter:
+		text := strings.TrimSpace(m.exprInput.Value())
+		if text == "" {
+			m.mode = models.ModeNormal
+			m.exprInput.Blur()
+			return m, nil
+		}
+		m.recordPrompt("computed", text)
+		m.addComputed(text)
+		return m, nil
+	}
+
+	before := m.exprInput.Value()
+	m.exprInput, cmd = m.exprInput.Update(msg)
+	if m.exprInput.Value() != before {
+		m.resetPromptHistory()
+	}
+	return m, cmd
+}
+
+// addComputed checks an expression against the header and inserts it as a
+// computed column. On error the prompt stays open with the cursor at the
+// offending position.
+func (m *Model) addComputed(text string) {
+	e, err := sql.ParseExpr(text)
+	if err == nil {
+		err = e.Bind(m.headerNames(m.currentSheet))
+	}
+	if err != nil {
+		var qerr *sql.Error
+		if errors.As(err, &qerr) {
+			value := m.exprInput.Value()
+			m.exprInput.SetCursor(len([]rune(value[:min(qerr.Pos, len(value))])))
+		}
+		m.status = models.StatusMsg{Message: "Computed column: " + err.Error(), Type: models.StatusError}
+		return
+	}
+
+	at := m.cursorCol + 1
+	m.mode = models.ModeNormal
+	m.exprInput.Blur()
+	m.isSelecting = false
+	m.execute(&computedEdit{
+		structEdit: structEdit{
+			sheet: m.currentSheet,
+			name:  fmt.Sprintf("Add computed column %s at %s", e.Name, ui.ColIndexToLetter(at)),
+			kind:  structInsertCols,
+			at:    at,
+			count: 1,
+		},
+		column: models.ComputedColumn{Col: at, Name: e.Name, Expr: e.Text, Header: m.headerRow()},
+	})
+	m.status = models.StatusMsg{
+		Message: fmt.Sprintf("Added computed column %s = %s", e.Name, e.Text),
+		Type:    models.StatusSuccess,
+	}
+}
+
+// headerNames returns the trimmed header of every column of a sheet
+func (m *Model) headerNames(sheetIdx int) []string {
+	return rowNames(&m.sheets[sheetIdx], m.headerRowOf(sheetIdx))
+}
+
+// rowNames returns the trimmed values of every column of a row
+func rowNames(sheet *models.Sheet, row int) []string {
+	names := make([]string, sheet.MaxCols)
+	for col := range names {
+		names[col] = strings.TrimSpace(sheet.CellAt(row, col).Value)
+	}
+	return names
+}
+
+// computer evaluates the computed columns of a sheet, compiling each
+// expression once against the names in the column's header row
+type computer struct {
+	sheet *models.Sheet
+	exprs map[int]*sql.Expr
+	errs  map[int]error
+}
+
+// newComputer returns the evaluator of a sheet's computed columns, or nil
+// when it has none
+func (m *Model) newComputer(sheetIdx int) *computer {
+	sheet := &m.sheets[sheetIdx]
+	if len(sheet.Computed) == 0 {
+		return nil
+	}
+	return &computer{
+		sheet: sheet,
+		exprs: make(map[int]*sql.Expr),
+		errs:  make(map[int]error),
+	}
+}
+
+// value returns the text of a cell, computing it for computed columns
+func (c *computer) value(row, col int) (string, error) {
+	return c.eval(row, col, 0)
+}
+
+// text returns the value of a cell, or an error marker
+func (c *computer) text(row, col int) string {
+	v, err := c.value(row, col)
+	if err != nil {
+		return computedError
+	}
+	return v
+}
+
+func (c *computer) eval(row, col, depth int) (string, error) {
+	cc := c.sheet.ComputedCell(row, col)
+	if cc == nil {
+		return c.sheet.CellAt(row, col).Value, nil
+	}
+	if depth > maxComputedDepth {
+		return "", fmt.Errorf("circular reference in %s", cc.Name)
+	}
+
+	e, err := c.expr(cc)
+	if err != nil {
+		return "", err
+	}
+	return e.Eval(func(ref int) (string, error) {
+		return c.eval(row, ref, depth+1)
+	})
+}
+
+// expr compiles the expression of a computed column against the header
+func (c *computer) expr(cc *models.ComputedColumn) (*sql.Expr, error) {
+	if e, ok := c.exprs[cc.Col]; ok {
+		return e, c.errs[cc.Col]
+	}
+	e, err := sql.ParseExpr(cc.Expr)
+	if err == nil {
+		err = e.Bind(rowNames(c.sheet, cc.Header))
+	}
+	c.exprs[cc.Col] = e
+	c.errs[cc.Col] = err
+	return e, err
+}
+
+// cellValues returns a reader of the values of a sheet's cells that
+// computes the cells of its computed columns
+func (m *Model) cellValues(sheetIdx int) func(row, col int) string {
+	if c := m.newComputer(sheetIdx); c != nil {
+		return c.text
+	}
+	sheet := &m.sheets[sheetIdx]
+	return func(row, col int) string { return sheet.CellAt(row, col).Value }
+}
+
+// materialize returns a copy of a sheet with the values of its computed
+// columns filled in, for export and save
+func (m *Model) materialize(sheetIdx int) models.Sheet {
+	sheet := m.sheets[sheetIdx]
+	c := m.newComputer(sheetIdx)
+	if c == nil {
+		return sheet
+	}
+
+	first := sheet.MaxRows
+	for _, cc := range sheet.Computed {
+		first = min(first, cc.Header+1)
+	}
+	rows := make([][]models.Cell, sheet.MaxRows)
+	copy(rows, sheet.Rows)
+	for row := first; row < sheet.MaxRows; row++ {
+		cells := make([]models.Cell, sheet.MaxCols)
+		copy(cells, rows[row])
+		for col := range cells {
+			cells[col].Row, cells[col].Col = row, col
+		}
+		for _, cc := range sheet.Computed {
+			if row > cc.Header {
+				cells[cc.Col].Value = c.text(row, cc.Col)
+				cells[cc.Col].Formula = ""
+			}
+		}
+		rows[row] = cells
+	}
+	sheet.Rows = rows
+	return sheet
+}
+
+// materializeAll materialises every sheet
+func (m *Model) materializeAll() []models.Sheet {
+	sheets := make([]models.Sheet, len(m.sheets))
+	for i := range m.sheets {
+		sheets[i] = m.materialize(i)
+	}
+	return sheets
+}
+
+// renderComputedBar renders the computed column prompt at the bottom of
+// the screen
+func (m Model) renderComputedBar() string {
+	t := theme.GetCurrentTheme()
+	prompt := m.styles.SearchPrompt.Render("ƒ ")
+	hint := lipgloss.NewStyle().
+		Foreground(t.DimText).
+		Render("  (Enter add, ↑/↓ history, Esc cancel)")
+	return m.styles.SearchBar.Render(prompt + m.exprInput.View() + hint)
+}
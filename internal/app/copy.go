@@ -0,0 +1,139 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vex/internal/clipboard"
+	"github.com/vex/internal/loader"
+	"github.com/vex/internal/theme"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+// cellText returns the text shown for a cell, honouring the formula toggle
+func (m *Model) cellText(cell models.Cell) string {
+	if m.showFormulas && cell.Formula != "" {
+		return "=" + cell.Formula
+	}
+	return cell.Value
+}
+
+// copyBounds returns the selected range, or the whole sheet without a selection
+func (m *Model) copyBounds() (startRow, startCol, endRow, endCol int) {
+	if m.isSelecting {
+		startRow, endRow = m.selectStart[0], m.selectEnd[0]
+		startCol, endCol = m.selectStart[1], m.selectEnd[1]
+		if startRow > endRow {
+			startRow, endRow = endRow, startRow
+		}
+		if startCol > endCol {
+			startCol, endCol = endCol, startCol
+		}
+		return startRow, startCol, endRow, endCol
+	}
+	sheet := m.sheets[m.currentSheet]
+	return 0, 0, sheet.MaxRows - 1, sheet.MaxCols - 1
+}
+
+// copyBlock collects the cell texts of the copy range
+func (m *Model) copyBlock() [][]string {
+	sheet := m.sheets[m.currentSheet]
+	value := m.cellValues(m.currentSheet)
+	startRow, startCol, endRow, endCol := m.copyBounds()
+
+	block := make([][]string, 0, ui.Max(0, endRow-startRow+1))
+	for row := startRow; row <= endRow; row++ {
+		values := make([]string, 0, ui.Max(0, endCol-startCol+1))
+		for col := startCol; col <= endCol; col++ {
+			cell := sheet.CellAt(row, col)
+			cell.Value = value(row, col)
+			values = append(values, m.cellText(cell))
+		}
+		block = append(block, values)
+	}
+	return block
+}
+
+// updateCopyAs handles the copy format modal
+func (m Model) updateCopyAs(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.mode = models.ModeNormal
+	case "up", "k":
+		if m.copyFormat > 0 {
+			m.copyFormat--
+		}
+	case "down", "j":
+		if m.copyFormat < len(loader.CopyFormats)-1 {
+			m.copyFormat++
+		}
+	case "1", "2", "3", "4", "5":
+		m.copyFormat = int(msg.String()[0] - '1')
+		m.copyAs(loader.CopyFormats[m.copyFormat])
+		m.mode = models.ModeNormal
+	case "enter":
+		m.copyAs(loader.CopyFormats[m.copyFormat])
+		m.mode = models.ModeNormal
+	}
+	return m, nil
+}
+
+// copyAs copies the selection or sheet to the clipboard in the given format
+func (m *Model) copyAs(format loader.CopyFormat) {
+	block := m.copyBlock()
+	text, err := loader.FormatBlock(block, format)
+	if err != nil {
+		m.status = models.StatusMsg{Message: err.Error(), Type: models.StatusError}
+		return
+	}
+	backend, err := clipboard.WriteAll(text)
+	if err != nil {
+		m.status = models.StatusMsg{Message: "Failed to copy: " + err.Error(), Type: models.StatusError}
+		return
+	}
+
+	rows, cols := pasteSize(block)
+	m.status = models.StatusMsg{
+		Message: fmt.Sprintf("Copied %d×%d as %s%s", rows, cols, format, clipboardNote(backend)),
+		Type:    models.StatusSuccess,
+	}
+}
+
+// renderCopyAs renders the copy format modal
+func (m Model) renderCopyAs() string {
+	t := theme.GetCurrentTheme()
+
+	scope := "Whole sheet"
+	if m.isSelecting {
+		startRow, startCol, endRow, endCol := m.copyBounds()
+		scope = fmt.Sprintf("Selection %s:%s", ui.CellRef(startRow, startCol), ui.CellRef(endRow, endCol))
+	}
+
+	content := m.styles.ModalTitle.Render("📋 Copy As") + "\n\n"
+	content += m.styles.ModalKey.Render("Range: ") + m.styles.ModalValue.Render(scope) + "\n\n"
+
+	descriptions := map[loader.CopyFormat]string{
+		loader.FormatTSV:      "paste into spreadsheets",
+		loader.FormatCSV:      "comma-separated text",
+		loader.FormatMarkdown: "table for tickets and docs",
+		loader.FormatHTML:     "table for email",
+		loader.FormatJSON:     "array of objects for code",
+	}
+	for i, format := range loader.CopyFormats {
+		line := fmt.Sprintf("%d. %-9s %s", i+1, format, descriptions[format])
+		if i == m.copyFormat {
+			content += lipgloss.NewStyle().Foreground(t.Accent).Bold(true).Render("→ "+line) + "\n"
+		} else {
+			content += lipgloss.NewStyle().Foreground(t.Text).Render("  "+line) + "\n"
+		}
+	}
+
+	content += "\n" + lipgloss.NewStyle().
+		Foreground(t.DimText).
+		Italic(true).
+		Render("Press 1-5 or Enter to copy, Esc to cancel")
+
+	return m.styles.Modal.Width(56).Render(content)
+}
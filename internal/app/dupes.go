@@ -0,0 +1,309 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vex/internal/dupes"
+	"github.com/vex/internal/pivot"
+	"github.com/vex/internal/theme"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+// dupListHeight is the number of columns or groups shown at once in the
+// duplicates dialog
+const dupListHeight = 12
+
+// startDuplicates opens the duplicates dialog, showing the groups already
+// found in the current sheet if there are any
+func (m Model) startDuplicates() (tea.Model, tea.Cmd) {
+	if m.dupSheet != m.currentSheet {
+		m.dupSheet = -1
+		m.dupCols = nil
+		m.dupGroups = nil
+		m.dupRows = nil
+		m.dupResults = false
+	}
+	m.dupCursor = 0
+	if !m.dupResults {
+		m.dupCursor = m.cursorCol
+	}
+	m.mode = models.ModeDuplicates
+	return m, nil
+}
+
+// updateDuplicates handles the duplicates dialog
+func (m Model) updateDuplicates(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.dupResults {
+		return m.updateDuplicateGroups(msg)
+	}
+
+	maxCol := ui.Max(0, m.sheets[m.currentSheet].MaxCols-1)
+	switch msg.String() {
+	case "esc", "q":
+		m.mode = models.ModeNormal
+	case "up", "k":
+		if m.dupCursor > 0 {
+			m.dupCursor--
+		}
+	case "down", "j":
+		if m.dupCursor < maxCol {
+			m.dupCursor++
+		}
+	case " ":
+		m.dupCols = toggleField(m.dupCols, m.dupCursor)
+	case "a":
+		m.dupCols = nil
+	case "i":
+		m.dupIgnoreCase = !m.dupIgnoreCase
+	case "tab":
+		if m.dupGroups != nil {
+			m.dupResults = true
+			m.dupCursor = 0
+		}
+	case "enter":
+		m.findDuplicates()
+	}
+	return m, nil
+}
+
+// updateDuplicateGroups handles the list of duplicate groups
+func (m Model) updateDuplicateGroups(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.mode = models.ModeNormal
+	case "up", "k":
+		if m.dupCursor > 0 {
+			m.dupCursor--
+		}
+	case "down", "j":
+		if m.dupCursor < len(m.dupGroups)-1 {
+			m.dupCursor++
+		}
+	case "tab":
+		m.dupResults = false
+		m.dupCursor = m.cursorCol
+	case "enter":
+		if m.dupCursor < len(m.dupGroups) {
+			m.mode = models.ModeNormal
+			m.pushJumpHistory()
+			m.moveTo(position{sheet: m.dupSheet, row: m.dupGroups[m.dupCursor].Rows[0], col: -1})
+		}
+	case "f":
+		m.removeDuplicates(dupes.KeepFirst)
+	case "l":
+		m.removeDuplicates(dupes.KeepLast)
+	case "x":
+		m.removeDuplicates(dupes.KeepNone)
+	case "X":
+		m.clearDuplicates()
+		m.mode = models.ModeNormal
+		m.status = models.StatusMsg{Message: "Duplicate highlights cleared", Type: models.StatusInfo}
+	}
+	return m, nil
+}
+
+// dupOptions returns the options of the current duplicate search
+func (m *Model) dupOptions() dupes.Options {
+	return dupes.Options{Cols: m.dupCols, IgnoreCase: m.dupIgnoreCase, HeaderRow: m.headerRowOf(m.dupSheet)}
+}
+
+// dupSkip returns the rows of the duplicates sheet the autofilter hides
+func (m *Model) dupSkip() func(row int) bool {
+	f := m.sheets[m.dupSheet].Filter
+	return func(row int) bool { return f != nil && f.IsHidden(row) }
+}
+
+// findDuplicates searches the current sheet for duplicate rows and
+// highlights them
+func (m *Model) findDuplicates() {
+	m.dupSheet = m.currentSheet
+	sheet := m.materialize(m.dupSheet)
+	m.dupGroups = dupes.Find(&sheet, m.dupOptions(), m.dupSkip())
+	m.dupRows = dupes.Rows(m.dupGroups)
+
+	if len(m.dupGroups) == 0 {
+		m.mode = models.ModeNormal
+		m.status = models.StatusMsg{Message: "No duplicate rows by " + m.dupKeyLabel(), Type: models.StatusSuccess}
+		return
+	}
+	m.dupResults = true
+	m.dupCursor = 0
+	m.status = models.StatusMsg{
+		Message: fmt.Sprintf("%d duplicate group(s), %d rows by %s", len(m.dupGroups), len(m.dupRows), m.dupKeyLabel()),
+		Type:    models.StatusWarning,
+	}
+}
+
+// refreshDuplicates repeats the duplicate search after the sheet changes
+func (m *Model) refreshDuplicates() {
+	if m.dupSheet < 0 || m.dupGroups == nil {
+		return
+	}
+	sheet := m.materialize(m.dupSheet)
+	m.dupGroups = dupes.Find(&sheet, m.dupOptions(), m.dupSkip())
+	m.dupRows = dupes.Rows(m.dupGroups)
+	m.dupCursor = ui.Min(m.dupCursor, ui.Max(0, len(m.dupGroups)-1))
+}
+
+// clearDuplicates forgets the duplicate search and its highlights
+func (m *Model) clearDuplicates() {
+	m.dupGroups = nil
+	m.dupRows = nil
+	m.dupResults = false
+}
+
+// removeDuplicates copies the rows the autofilter shows, without their
+// duplicates, into a new sheet. The status says how many hidden rows were
+// left out along with the duplicates.
+func (m *Model) removeDuplicates(keep dupes.Keep) {
+	source := m.sheets[m.dupSheet].Name
+	header := m.headerRowOf(m.dupSheet)
+	sheet := m.materialize(m.dupSheet)
+	values := dupes.Remove(&sheet, m.dupGroups, keep, header, m.dupSkip())
+	shown := m.shownDataRows(m.dupSheet)
+	removed := shown - (len(values) - header - 1)
+	hidden := ui.Max(0, sheet.MaxRows-header-1) - shown
+
+	message := fmt.Sprintf("Deduped %s (%s): removed %d row(s)", source, keep, removed)
+	if hidden > 0 {
+		message += fmt.Sprintf(", left out %d row(s) hidden by the filter", hidden)
+	}
+	m.mode = models.ModeNormal
+	m.addVirtualSheet("Deduped", values)
+	m.status = models.StatusMsg{Message: message, Type: models.StatusSuccess}
+}
+
+// shownDataRows counts the rows below the header the autofilter shows
+func (m *Model) shownDataRows(sheetIdx int) int {
+	sheet := &m.sheets[sheetIdx]
+	if sheet.Filter != nil {
+		return sheet.Filter.Shown
+	}
+	return ui.Max(0, sheet.MaxRows-m.headerRowOf(sheetIdx)-1)
+}
+
+// isDuplicateRow reports whether a row of the current sheet is highlighted
+// as a duplicate
+func (m *Model) isDuplicateRow(row int) bool {
+	return m.dupSheet == m.currentSheet && m.dupRows[row]
+}
+
+// dupKeyLabel describes the columns duplicates are matched on
+func (m *Model) dupKeyLabel() string {
+	label := "whole row"
+	if len(m.dupCols) > 0 {
+		sheet := &m.sheets[m.currentSheet]
+		names := make([]string, len(m.dupCols))
+		for i, col := range m.dupCols {
+			names[i] = pivot.FieldName(sheet, m.headerRow(), col)
+		}
+		label = strings.Join(names, ", ")
+	}
+	if m.dupIgnoreCase {
+		label += " (ignoring case)"
+	}
+	return label
+}
+
+// renderDuplicates renders the duplicates dialog
+func (m Model) renderDuplicates() string {
+	t := theme.GetCurrentTheme()
+	dim := lipgloss.NewStyle().Foreground(t.DimText)
+	text := lipgloss.NewStyle().Foreground(t.Text)
+	selected := lipgloss.NewStyle().Foreground(t.Accent).Bold(true)
+	checked := lipgloss.NewStyle().Foreground(t.Success).Bold(true)
+
+	sheet := &m.sheets[m.currentSheet]
+	content := m.styles.ModalTitle.Render("≡ Duplicate Rows") + "\n\n"
+	content += m.styles.ModalKey.Render("Match: ") + m.styles.ModalValue.Render(m.dupKeyLabel()) + "\n\n"
+
+	var help []string
+	if m.dupResults {
+		content += text.Render(fmt.Sprintf("%d group(s), %d rows", len(m.dupGroups), len(m.dupRows))) + "\n\n"
+
+		start := ui.Max(0, ui.Min(m.dupCursor-dupListHeight/2, len(m.dupGroups)-dupListHeight))
+		end := ui.Min(start+dupListHeight, len(m.dupGroups))
+		if start > 0 {
+			content += dim.Render(fmt.Sprintf("    ↑ %d more", start)) + "\n"
+		}
+		for i := start; i < end; i++ {
+			g := m.dupGroups[i]
+			line := fmt.Sprintf("%-24s %s", ui.Truncate(groupRowsLabel(g), 24), ui.Truncate(m.groupPreview(g), 36))
+			if i == m.dupCursor {
+				content += selected.Render("→ "+line) + "\n"
+			} else {
+				content += "  " + text.Render(line) + "\n"
+			}
+		}
+		if end < len(m.dupGroups) {
+			content += dim.Render(fmt.Sprintf("    ↓ %d more", len(m.dupGroups)-end)) + "\n"
+		}
+		help = []string{"Enter go to", "f keep first", "l keep last", "x drop all", "X clear", "Tab columns", "Esc close"}
+	} else {
+		start := ui.Max(0, ui.Min(m.dupCursor-dupListHeight/2, sheet.MaxCols-dupListHeight))
+		end := ui.Min(start+dupListHeight, sheet.MaxCols)
+		if start > 0 {
+			content += dim.Render(fmt.Sprintf("    ↑ %d more", start)) + "\n"
+		}
+		for col := start; col < end; col++ {
+			box := dim.Render("[ ] ")
+			if fieldIndex(m.dupCols, col) > 0 {
+				box = checked.Render("[x] ")
+			}
+			name := fmt.Sprintf("%-3s %s", ui.ColIndexToLetter(col), ui.Truncate(pivot.FieldName(sheet, m.headerRow(), col), 40))
+			if col == m.dupCursor {
+				content += selected.Render("→ ") + box + selected.Render(name) + "\n"
+			} else {
+				content += "  " + box + text.Render(name) + "\n"
+			}
+		}
+		if end < sheet.MaxCols {
+			content += dim.Render(fmt.Sprintf("    ↓ %d more", sheet.MaxCols-end)) + "\n"
+		}
+		help = []string{"Space key column", "a whole row", "i ignore case", "Enter find"}
+		if m.dupGroups != nil {
+			help = append(help, "Tab groups")
+		}
+		help = append(help, "Esc cancel")
+	}
+
+	content += "\n" + dim.Italic(true).Render(strings.Join(help, " • "))
+	return m.styles.Modal.Width(72).Render(content)
+}
+
+// groupRowsLabel lists the row numbers of a group
+func groupRowsLabel(g dupes.Group) string {
+	nums := make([]string, len(g.Rows))
+	for i, r := range g.Rows {
+		nums[i] = strconv.Itoa(r + 1)
+	}
+	return fmt.Sprintf("%d× rows %s", len(g.Rows), strings.Join(nums, ", "))
+}
+
+// groupPreview shows the key values of a group's first row
+func (m *Model) groupPreview(g dupes.Group) string {
+	sheet := &m.sheets[m.dupSheet]
+	cols := m.dupCols
+	if len(cols) == 0 {
+		cols = make([]int, sheet.MaxCols)
+		for i := range cols {
+			cols[i] = i
+		}
+	}
+	c := m.newComputer(m.dupSheet)
+	values := make([]string, len(cols))
+	for i, col := range cols {
+		if c != nil {
+			values[i] = c.text(g.Rows[0], col)
+		} else {
+			values[i] = sheet.CellAt(g.Rows[0], col).Value
+		}
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return strings.Join(values, " │ ")
+}
@@ -0,0 +1,173 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vex/internal/loader"
+	"github.com/vex/internal/theme"
+	"github.com/vex/pkg/models"
+)
+
+// startEdit enters edit mode for the cursor cell
+func (m Model) startEdit() (tea.Model, tea.Cmd) {
+	sheet := m.sheets[m.currentSheet]
+	if sheet.ComputedCell(m.cursorRow, m.cursorCol) != nil {
+		m.status = models.StatusMsg{Message: "Computed cells follow their column's expression", Type: models.StatusWarning}
+		return m, nil
+	}
+	cell := sheet.CellAt(m.cursorRow, m.cursorCol)
+
+	value := cell.Value
+	if cell.Formula != "" {
+		value = "=" + cell.Formula
+	}
+
+	m.mode = models.ModeEdit
+	m.editInput.SetValue(value)
+	m.editInput.CursorEnd()
+	m.editInput.Focus()
+	return m, textinput.Blink
+}
+
+// updateEdit handles edit mode updates
+func (m Model) updateEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.Type {
+	case tea.KeyEscape:
+		m.mode = models.ModeNormal
+		m.editInput.Blur()
+		m.status = models.StatusMsg{Message: "Edit cancelled", Type: models.StatusInfo}
+		return m, nil
+
+	case tea.KeyEnter:
+		m.commitEdit(m.editInput.Value())
+		m.mode = models.ModeNormal
+		m.editInput.Blur()
+		return m, nil
+	}
+
+	m.editInput, cmd = m.editInput.Update(msg)
+	return m, cmd
+}
+
+// commitEdit writes the edited text into the cursor cell. Text starting
+// with "=" replaces the formula, anything else replaces the value. vex does
+// not evaluate formulas, so a new formula leaves the cell without a value
+// rather than showing the old formula's result.
+func (m *Model) commitEdit(text string) {
+	sheet := m.sheets[m.currentSheet]
+	old := sheet.CellAt(m.cursorRow, m.cursorCol)
+
+	updated := old
+	if strings.HasPrefix(text, "=") && len(text) > 1 {
+		updated.Formula = text[1:]
+		if updated.Formula != old.Formula {
+			updated.Value = ""
+		}
+	} else {
+		updated.Value = text
+		updated.Formula = ""
+	}
+
+	if updated.Value == old.Value && updated.Formula == old.Formula {
+		m.status = models.StatusMsg{Message: "No changes", Type: models.StatusInfo}
+		return
+	}
+
+	updated.Row, updated.Col = m.cursorRow, m.cursorCol
+	m.execute(&cellEdit{
+		sheet:   m.currentSheet,
+		name:    fmt.Sprintf("Edit %s", m.cellLabel(m.cursorRow, m.cursorCol)),
+		changes: []cellChange{{row: m.cursorRow, col: m.cursorCol, before: old, after: updated}},
+	})
+}
+
+// isDirty reports whether any sheet has unsaved changes
+func (m *Model) isDirty() bool {
+	for _, sheet := range m.sheets {
+		if sheet.Dirty && !sheet.Virtual {
+			return true
+		}
+	}
+	return false
+}
+
+// saveFile writes unsaved changes back to the original file
+func (m *Model) saveFile() bool {
+	if !m.isDirty() {
+		m.status = models.StatusMsg{Message: "No unsaved changes", Type: models.StatusInfo}
+		return true
+	}
+
+	if err := loader.SaveFile(m.filename, m.materializeAll()); err != nil {
+		m.status = models.StatusMsg{
+			Message: fmt.Sprintf("Save failed: %v", err),
+			Type:    models.StatusError,
+		}
+		return false
+	}
+
+	for i := range m.sheets {
+		m.sheets[i].Dirty = false
+		m.sheets[i].Edits = nil
+	}
+	m.saves++
+	m.status = models.StatusMsg{
+		Message: fmt.Sprintf("✓ Saved %s", filepath.Base(m.filename)),
+		Type:    models.StatusSuccess,
+	}
+	return true
+}
+
+// updateConfirmQuit handles the unsaved changes prompt shown on quit
+func (m Model) updateConfirmQuit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "s":
+		if m.saveFile() {
+			return m, tea.Quit
+		}
+		m.mode = models.ModeNormal
+	case "y":
+		return m, tea.Quit
+	case "esc", "n", "q", "ctrl+c":
+		m.mode = models.ModeNormal
+		m.status = models.StatusMsg{Message: "Quit cancelled", Type: models.StatusInfo}
+	}
+	return m, nil
+}
+
+// renderEditBar renders the formula bar while a cell is being edited
+func (m Model) renderEditBar() string {
+	t := theme.GetCurrentTheme()
+	ref := lipgloss.NewStyle().
+		Foreground(t.Warning).
+		Bold(true).
+		Render(m.cellLabel(m.cursorRow, m.cursorCol) + " ✎ ")
+	return m.styles.FormulaBar.Render(ref + m.editInput.View())
+}
+
+// renderConfirmQuit renders the unsaved changes modal
+func (m Model) renderConfirmQuit() string {
+	t := theme.GetCurrentTheme()
+
+	content := m.styles.ModalTitle.Render("⚠ Unsaved Changes") + "\n\n"
+	// Like isDirty, leave out generated and alongside sheets, which are
+	// not saved
+	for _, sheet := range m.sheets {
+		if sheet.Dirty && !sheet.Virtual {
+			content += lipgloss.NewStyle().Foreground(t.Text).Render("  • "+sheet.Name) + "\n"
+		}
+	}
+	content += "\n"
+	content += m.styles.ModalKey.Render("s") + m.styles.ModalValue.Render("  Save and quit") + "\n"
+	content += m.styles.ModalKey.Render("y") + m.styles.ModalValue.Render("  Quit without saving") + "\n"
+	content += m.styles.ModalKey.Render("esc") + m.styles.ModalValue.Render(" Keep editing")
+
+	return m.styles.Modal.Width(50).Render(content)
+}
@@ -0,0 +1,340 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vex/internal/loader"
+	"github.com/vex/internal/theme"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+// filterListHeight is the number of values shown at once in the filter dialog
+const filterListHeight = 12
+
+// filterItem is one distinct value of the filtered column
+type filterItem struct {
+	value string // Trimmed value, "" for blanks
+	count int
+	keep  bool
+}
+
+// rowHidden reports whether the autofilter hides a row of the current sheet
+func (m *Model) rowHidden(row int) bool {
+	f := m.sheets[m.currentSheet].Filter
+	return f != nil && f.IsHidden(row)
+}
+
+// stepRows moves n shown rows from row, down for positive n and up for
+// negative n, stopping at the edges of the sheet
+func (m *Model) stepRows(row, n int) int {
+	sheet := m.sheets[m.currentSheet]
+	maxRow := ui.Max(0, sheet.MaxRows-1)
+	if sheet.Filter == nil {
+		return clamp(row+n, 0, maxRow)
+	}
+
+	dir := 1
+	if n < 0 {
+		dir, n = -1, -n
+	}
+	for r := row + dir; n > 0 && r >= 0 && r <= maxRow; r += dir {
+		if !m.rowHidden(r) {
+			row = r
+			n--
+		}
+	}
+	return row
+}
+
+// shownRows counts the rows in [from, to) not hidden by the autofilter
+func (m *Model) shownRows(from, to int) int {
+	if m.sheets[m.currentSheet].Filter == nil {
+		return to - from
+	}
+	count := 0
+	for r := from; r < to; r++ {
+		if !m.rowHidden(r) {
+			count++
+		}
+	}
+	return count
+}
+
+// snapToShownRow moves the cursor off a hidden row, preferring the next
+// shown row below it
+func (m *Model) snapToShownRow() {
+	if !m.rowHidden(m.cursorRow) {
+		return
+	}
+	if row := m.stepRows(m.cursorRow, 1); !m.rowHidden(row) {
+		m.cursorRow = row
+	} else {
+		m.cursorRow = m.stepRows(m.cursorRow, -1)
+	}
+	m.adjustViewport()
+}
+
+// refreshFilter re-applies the autofilter of a sheet, e.g. after an edit
+// changed the values or moved the rows they were computed for
+func (m *Model) refreshFilter(sheetIdx int) {
+	loader.ApplyAutoFilter(&m.sheets[sheetIdx], m.cellValues(sheetIdx))
+}
+
+// setColumnFilter replaces the filter of one column of the current sheet,
+// removing it when cf is nil, and re-applies the autofilter
+func (m *Model) setColumnFilter(col int, cf *models.ColumnFilter) {
+	sheet := &m.sheets[m.currentSheet]
+	if sheet.Filter == nil {
+		if cf == nil {
+			return
+		}
+		sheet.Filter = &models.AutoFilter{}
+	}
+
+	f := sheet.Filter
+	columns := f.Columns[:0]
+	for _, c := range f.Columns {
+		if c.Col != col {
+			columns = append(columns, c)
+		}
+	}
+	if cf != nil {
+		columns = append(columns, *cf)
+	}
+	f.Columns = columns
+
+	if len(f.Columns) == 0 {
+		sheet.Filter = nil
+		m.status = models.StatusMsg{Message: "Filter cleared", Type: models.StatusInfo}
+		return
+	}
+	loader.ApplyAutoFilter(sheet, m.cellValues(m.currentSheet))
+	m.snapToShownRow()
+	m.status = models.StatusMsg{
+		Message: fmt.Sprintf("Filter: %d of %d rows shown", f.Shown, sheet.MaxRows-f.HeaderRow-1),
+		Type:    models.StatusSuccess,
+	}
+}
+
+// clearFilters removes every filter from the current sheet
+func (m *Model) clearFilters() {
+	m.sheets[m.currentSheet].Filter = nil
+	m.status = models.StatusMsg{Message: "All filters cleared", Type: models.StatusInfo}
+}
+
+// startFilter opens the autofilter dialog for the cursor column, listing
+// the values of the rows that pass the filters on other columns
+func (m Model) startFilter() (tea.Model, tea.Cmd) {
+	sheet := &m.sheets[m.currentSheet]
+	col := m.cursorCol
+	header := m.headerRow()
+	var current *models.ColumnFilter
+	if sheet.Filter != nil {
+		current = sheet.Filter.Column(col)
+	}
+
+	value := m.cellValues(m.currentSheet)
+	counts := make(map[string]int)
+	for row := header + 1; row < sheet.MaxRows; row++ {
+		if loader.RowPasses(sheet, value, row, col) {
+			counts[strings.TrimSpace(value(row, col))]++
+		}
+	}
+	if current != nil {
+		// Keep values the filter refers to even if no row has them any more
+		for v := range current.Values {
+			if _, ok := counts[v]; !ok {
+				counts[v] = 0
+			}
+		}
+	}
+
+	m.filterItems = make([]filterItem, 0, len(counts))
+	for v, n := range counts {
+		keep := current == nil || current.Values == nil || current.Values[v]
+		m.filterItems = append(m.filterItems, filterItem{value: v, count: n, keep: keep})
+	}
+	sort.Slice(m.filterItems, func(i, j int) bool {
+		return loader.CompareValues(m.filterItems[i].value, m.filterItems[j].value) < 0
+	})
+
+	m.filterCol = col
+	m.filterCursor = 0
+	m.filterEditing = false
+	m.filterCond.Blur()
+	m.filterCond.SetValue("")
+	if current != nil {
+		m.filterCond.SetValue(loader.FormatCondition(current.Op, current.Operand))
+	}
+	m.mode = models.ModeFilter
+	return m, nil
+}
+
+// updateFilter handles the autofilter dialog. The cursor is on
+// "(Select all)" at 0 and on filterItems[i-1] otherwise.
+func (m Model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = models.ModeNormal
+		return m, nil
+	case "tab", "shift+tab":
+		m.filterEditing = !m.filterEditing
+		if m.filterEditing {
+			m.filterCond.Focus()
+			m.filterCond.CursorEnd()
+			return m, textinput.Blink
+		}
+		m.filterCond.Blur()
+		return m, nil
+	case "enter":
+		op, operand, err := loader.ParseCondition(m.filterCond.Value())
+		if err != nil {
+			m.status = models.StatusMsg{Message: "Condition: " + err.Error(), Type: models.StatusError}
+			return m, nil
+		}
+		m.mode = models.ModeNormal
+		m.setColumnFilter(m.filterCol, m.columnFilter(op, operand))
+		return m, nil
+	}
+
+	if m.filterEditing {
+		var cmd tea.Cmd
+		m.filterCond, cmd = m.filterCond.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "q":
+		m.mode = models.ModeNormal
+	case "up", "k":
+		if m.filterCursor > 0 {
+			m.filterCursor--
+		}
+	case "down", "j":
+		if m.filterCursor < len(m.filterItems) {
+			m.filterCursor++
+		}
+	case "pgup":
+		m.filterCursor = ui.Max(0, m.filterCursor-filterListHeight)
+	case "pgdown":
+		m.filterCursor = ui.Min(len(m.filterItems), m.filterCursor+filterListHeight)
+	case " ":
+		if m.filterCursor == 0 {
+			m.setAllFilterItems(!m.allFilterItemsKept())
+		} else {
+			item := &m.filterItems[m.filterCursor-1]
+			item.keep = !item.keep
+		}
+	case "a":
+		m.setAllFilterItems(!m.allFilterItemsKept())
+	case "i":
+		for i := range m.filterItems {
+			m.filterItems[i].keep = !m.filterItems[i].keep
+		}
+	case "x":
+		m.mode = models.ModeNormal
+		m.setColumnFilter(m.filterCol, nil)
+	case "X":
+		m.mode = models.ModeNormal
+		m.clearFilters()
+	}
+	return m, nil
+}
+
+// columnFilter builds the filter chosen in the dialog, or nil if it would
+// keep every row
+func (m *Model) columnFilter(op, operand string) *models.ColumnFilter {
+	cf := &models.ColumnFilter{Col: m.filterCol, Op: op, Operand: operand}
+	if !m.allFilterItemsKept() {
+		cf.Values = make(map[string]bool)
+		for _, item := range m.filterItems {
+			if item.keep {
+				cf.Values[item.value] = true
+			}
+		}
+	}
+	if cf.Values == nil && cf.Op == "" {
+		return nil
+	}
+	return cf
+}
+
+// allFilterItemsKept reports whether every value is checked
+func (m *Model) allFilterItemsKept() bool {
+	for _, item := range m.filterItems {
+		if !item.keep {
+			return false
+		}
+	}
+	return true
+}
+
+// setAllFilterItems checks or unchecks every value
+func (m *Model) setAllFilterItems(keep bool) {
+	for i := range m.filterItems {
+		m.filterItems[i].keep = keep
+	}
+}
+
+// renderFilter renders the autofilter dialog
+func (m Model) renderFilter() string {
+	t := theme.GetCurrentTheme()
+	dim := lipgloss.NewStyle().Foreground(t.DimText)
+	text := lipgloss.NewStyle().Foreground(t.Text)
+	selected := lipgloss.NewStyle().Foreground(t.Accent).Bold(true)
+	checked := lipgloss.NewStyle().Foreground(t.Success).Render("[x]")
+	unchecked := dim.Render("[ ]")
+
+	sheet := m.sheets[m.currentSheet]
+	name := ui.ColIndexToLetter(m.filterCol)
+	if h := strings.TrimSpace(sheet.CellAt(m.headerRow(), m.filterCol).Value); h != "" {
+		name += " (" + ui.Truncate(h, 30) + ")"
+	}
+	content := m.styles.ModalTitle.Render("▾ Filter "+name) + "\n\n"
+
+	box := func(keep bool) string {
+		if keep {
+			return checked
+		}
+		return unchecked
+	}
+	line := func(i int, s string) string {
+		if i == m.filterCursor && !m.filterEditing {
+			return selected.Render("→ ") + s + "\n"
+		}
+		return "  " + s + "\n"
+	}
+
+	content += line(0, box(m.allFilterItemsKept())+text.Render(" (Select all)"))
+
+	start := ui.Max(0, ui.Min(m.filterCursor-filterListHeight/2, len(m.filterItems)-filterListHeight))
+	end := ui.Min(start+filterListHeight, len(m.filterItems))
+	if start > 0 {
+		content += dim.Render(fmt.Sprintf("    ↑ %d more", start)) + "\n"
+	}
+	for i := start; i < end; i++ {
+		item := m.filterItems[i]
+		value := ui.Truncate(item.value, 40)
+		if item.value == "" {
+			value = "(Blanks)"
+		}
+		content += line(i+1, box(item.keep)+text.Render(" "+value)+dim.Render(fmt.Sprintf("  %d", item.count)))
+	}
+	if end < len(m.filterItems) {
+		content += dim.Render(fmt.Sprintf("    ↓ %d more", len(m.filterItems)-end)) + "\n"
+	}
+
+	content += "\n" + m.styles.ModalKey.Render("Condition: ") + m.filterCond.View() + "\n"
+	content += dim.Render("  > 100, <= 2024-01-31, != done, contains north, blank, nonblank") + "\n\n"
+	content += dim.Italic(true).Render(strings.Join([]string{
+		"space toggle", "a all", "i invert", "tab condition", "x/X clear column/all", "Enter apply", "Esc cancel",
+	}, " • "))
+
+	return m.styles.Modal.Width(72).Render(content)
+}
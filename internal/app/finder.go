@@ -0,0 +1,196 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vex/internal/fuzzy"
+	"github.com/vex/internal/theme"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+// maxFinderValues bounds the distinct cell values offered by the finder
+const maxFinderValues = 50000
+
+// finderKind is the kind of thing a finder entry jumps to
+type finderKind int
+
+const (
+	finderSheet finderKind = iota
+	finderHeader
+	finderValue
+)
+
+// finderItem is a candidate of the fuzzy finder
+type finderItem struct {
+	kind  finderKind
+	text  string
+	pos   position
+	count int // Occurrences of a cell value in the workbook
+}
+
+// startFinder collects sheet names, header names and distinct cell values
+// and opens the fuzzy finder
+func (m Model) startFinder() (tea.Model, tea.Cmd) {
+	var items []finderItem
+	for s, sheet := range m.sheets {
+		items = append(items, finderItem{kind: finderSheet, text: sheet.Name, pos: position{sheet: s}})
+	}
+	for s, sheet := range m.sheets {
+		header := m.headerRowOf(s)
+		if header >= len(sheet.Rows) {
+			continue
+		}
+		for _, cell := range sheet.Rows[header] {
+			if strings.TrimSpace(cell.Value) != "" {
+				items = append(items, finderItem{kind: finderHeader, text: cell.Value, pos: position{sheet: s, row: header, col: cell.Col}})
+			}
+		}
+	}
+
+	seen := make(map[string]int)
+	for s, sheet := range m.sheets {
+		header := m.headerRowOf(s)
+		for r := range sheet.Rows {
+			if r == header {
+				continue
+			}
+			for _, cell := range sheet.Rows[r] {
+				value := strings.TrimSpace(cell.Value)
+				if value == "" {
+					continue
+				}
+				if i, ok := seen[value]; ok {
+					items[i].count++
+					continue
+				}
+				if len(seen) >= maxFinderValues {
+					continue
+				}
+				seen[value] = len(items)
+				items = append(items, finderItem{kind: finderValue, text: value, pos: position{sheet: s, row: r, col: cell.Col}, count: 1})
+			}
+		}
+	}
+
+	m.finderItems = items
+	m.finderInput.SetValue("")
+	m.finderInput.Focus()
+	m.filterFinder()
+	m.mode = models.ModeFinder
+	return m, textinput.Blink
+}
+
+// filterFinder ranks the finder entries against the typed pattern
+func (m *Model) filterFinder() {
+	texts := make([]string, len(m.finderItems))
+	for i, item := range m.finderItems {
+		texts[i] = item.text
+	}
+	m.finderMatches = fuzzy.Rank(strings.TrimSpace(m.finderInput.Value()), texts)
+	m.finderCursor = 0
+}
+
+// updateFinder handles the fuzzy finder
+func (m Model) updateFinder(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.mode = models.ModeNormal
+		m.finderInput.Blur()
+		m.finderItems, m.finderMatches = nil, nil
+		return m, nil
+	case "up", "ctrl+p", "ctrl+k":
+		if m.finderCursor > 0 {
+			m.finderCursor--
+		}
+		return m, nil
+	case "down", "ctrl+n", "ctrl+j":
+		if m.finderCursor < len(m.finderMatches)-1 {
+			m.finderCursor++
+		}
+		return m, nil
+	case "enter":
+		if len(m.finderMatches) > 0 {
+			item := m.finderItems[m.finderMatches[m.finderCursor].Index]
+			m.pushJumpHistory()
+			m.moveTo(item.pos)
+			m.status = models.StatusMsg{Message: "→ " + m.cellLabel(m.cursorRow, m.cursorCol), Type: models.StatusInfo}
+		}
+		m.mode = models.ModeNormal
+		m.finderInput.Blur()
+		m.finderItems, m.finderMatches = nil, nil
+		return m, nil
+	}
+
+	before := m.finderInput.Value()
+	m.finderInput, cmd = m.finderInput.Update(msg)
+	if m.finderInput.Value() != before {
+		m.filterFinder()
+	}
+	return m, cmd
+}
+
+// renderFinder renders the fuzzy finder with matched characters emphasised
+func (m Model) renderFinder() string {
+	t := theme.GetCurrentTheme()
+	dim := lipgloss.NewStyle().Foreground(t.DimText)
+	text := lipgloss.NewStyle().Foreground(t.Text)
+	hit := lipgloss.NewStyle().Foreground(t.SearchMatch).Bold(true)
+	kinds := map[finderKind]string{finderSheet: "sheet ", finderHeader: "column", finderValue: "value "}
+
+	content := m.styles.ModalTitle.Render("🔎 Find") + "\n\n"
+	content += m.finderInput.View() + "\n"
+	content += dim.Render(fmt.Sprintf("%d/%d", len(m.finderMatches), len(m.finderItems))) + "\n\n"
+
+	const maxShown, width = 15, 40
+	start := ui.Max(0, m.finderCursor-maxShown+1)
+	for i := start; i < len(m.finderMatches) && i < start+maxShown; i++ {
+		match := m.finderMatches[i]
+		item := m.finderItems[match.Index]
+
+		marked := make(map[int]bool, len(match.Positions))
+		for _, p := range match.Positions {
+			marked[p] = true
+		}
+		var label strings.Builder
+		runes := []rune(strings.ReplaceAll(item.text, "\n", " "))
+		for j, r := range runes {
+			if j == width {
+				label.WriteString(text.Render("…"))
+				break
+			}
+			if marked[j] {
+				label.WriteString(hit.Render(string(r)))
+			} else {
+				label.WriteString(text.Render(string(r)))
+			}
+		}
+		label.WriteString(strings.Repeat(" ", ui.Max(0, width-len(runes))))
+
+		where := ""
+		switch item.kind {
+		case finderHeader:
+			where = m.sheetCellLabel(item.pos.sheet, item.pos.row, item.pos.col)
+		case finderValue:
+			where = m.sheetCellLabel(item.pos.sheet, item.pos.row, item.pos.col)
+			if item.count > 1 {
+				where += fmt.Sprintf(" ×%d", item.count)
+			}
+		}
+
+		prefix := "  "
+		if i == m.finderCursor {
+			prefix = lipgloss.NewStyle().Foreground(t.Accent).Bold(true).Render("→ ")
+		}
+		content += prefix + dim.Render(kinds[item.kind]+" ") + label.String() + " " + dim.Render(where) + "\n"
+	}
+
+	content += "\n" + dim.Italic(true).Render("↑/↓ select • Enter jump • Esc close")
+	return m.styles.Modal.Width(80).Render(content)
+}
@@ -0,0 +1,319 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vex/internal/condfmt"
+	"github.com/vex/internal/config"
+	"github.com/vex/internal/theme"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+// formatListHeight is how many rules the conditional format dialog shows
+const formatListHeight = 10
+
+// loadSessionFormats adds the rules saved for each sheet in an earlier run
+// ahead of the sheet's own formats. Rules that no longer fit the sheet,
+// e.g. because a column was renamed, are dropped.
+func (m *Model) loadSessionFormats() {
+	for i := range m.sheets {
+		sheet := &m.sheets[i]
+		texts := m.sessions[m.sessionKey(i)].Formats[sheet.Name]
+		var rules []models.FormatRule
+		for _, text := range texts {
+			if r, err := condfmt.ParseRule(text, m.headerNames(i), m.headerRowOf(i)); err == nil {
+				rules = append(rules, r)
+			}
+		}
+		sheet.Formats = append(rules, sheet.Formats...)
+	}
+}
+
+// sessionKey returns the key the session of a sheet's file is stored
+// under, or "" for generated sheets, which are not remembered
+func (m *Model) sessionKey(sheetIdx int) string {
+	sheet := &m.sheets[sheetIdx]
+	switch {
+	case sheet.Virtual:
+		return ""
+	case sheet.Source != "":
+		return config.SessionKey(sheet.Source)
+	}
+	return config.SessionKey(m.filename)
+}
+
+// saveSessionFormats stores the rules defined in vex for the current sheet
+func (m *Model) saveSessionFormats() {
+	key := m.sessionKey(m.currentSheet)
+	if key == "" || m.sessions == nil {
+		return
+	}
+	sheet := &m.sheets[m.currentSheet]
+	var texts []string
+	for _, r := range sheet.Formats {
+		if r.Rule != "" {
+			texts = append(texts, r.Rule)
+		}
+	}
+
+	session := m.sessions[key]
+	if session.Formats == nil {
+		session.Formats = make(map[string][]string)
+	}
+	if len(texts) == 0 {
+		delete(session.Formats, sheet.Name)
+	} else {
+		session.Formats[sheet.Name] = texts
+	}
+	if len(session.Formats) == 0 {
+		delete(m.sessions, key)
+	} else {
+		m.sessions[key] = session
+	}
+	if err := m.sessions.Save(); err != nil {
+		m.status = models.StatusMsg{Message: err.Error(), Type: models.StatusWarning}
+	}
+}
+
+// startFormats opens the conditional format rules of the current sheet
+func (m Model) startFormats() (tea.Model, tea.Cmd) {
+	m.mode = models.ModeFormats
+	m.formatCursor = 0
+	m.resetPromptHistory()
+	m.ruleInput.SetValue("")
+	// With no rules yet there is nothing to pick, so start typing one
+	m.formatEditing = len(m.sheets[m.currentSheet].Formats) == 0
+	if m.formatEditing {
+		m.ruleInput.Focus()
+		return m, textinput.Blink
+	}
+	m.ruleInput.Blur()
+	return m, nil
+}
+
+// updateFormats handles the conditional format dialog
+func (m Model) updateFormats(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.formatEditing {
+		switch msg.Type {
+		case tea.KeyEscape, tea.KeyTab, tea.KeyShiftTab:
+			m.formatEditing = false
+			m.ruleInput.Blur()
+			return m, nil
+		case tea.KeyUp:
+			m.browsePromptHistory(&m.ruleInput, "format", -1)
+			return m, nil
+		case tea.KeyDown:
+			m.browsePromptHistory(&m.ruleInput, "format", 1)
+			return m, nil
+		case tea.KeyEnter:
+			m.addFormat()
+			return m, nil
+		}
+		before := m.ruleInput.Value()
+		var cmd tea.Cmd
+		m.ruleInput, cmd = m.ruleInput.Update(msg)
+		if m.ruleInput.Value() != before {
+			m.resetPromptHistory()
+		}
+		return m, cmd
+	}
+
+	rules := m.sheets[m.currentSheet].Formats
+	switch msg.String() {
+	case "esc", "q":
+		m.mode = models.ModeNormal
+	case "up", "k":
+		m.formatCursor = ui.Max(0, m.formatCursor-1)
+	case "down", "j":
+		m.formatCursor = ui.Max(0, ui.Min(len(rules)-1, m.formatCursor+1))
+	case "a", "tab", "shift+tab":
+		m.formatEditing = true
+		m.ruleInput.Focus()
+		m.ruleInput.CursorEnd()
+		return m, textinput.Blink
+	case "x", "delete":
+		m.deleteFormat()
+	case "t":
+		m.formatsOff = !m.formatsOff
+		m.refreshFormatter()
+		state := "shown"
+		if m.formatsOff {
+			state = "hidden"
+		}
+		m.status = models.StatusMsg{Message: "Conditional formats " + state, Type: models.StatusInfo}
+	}
+	return m, nil
+}
+
+// addFormat parses the typed rule and puts it ahead of the sheet's other
+// rules. On error the field keeps its text so it can be corrected.
+func (m *Model) addFormat() {
+	text := strings.TrimSpace(m.ruleInput.Value())
+	if text == "" {
+		m.formatEditing = false
+		m.ruleInput.Blur()
+		return
+	}
+	rule, err := condfmt.ParseRule(text, m.headerNames(m.currentSheet), m.headerRow())
+	if err != nil {
+		m.status = models.StatusMsg{Message: "Rule: " + err.Error(), Type: models.StatusError}
+		return
+	}
+	m.recordPrompt("format", text)
+
+	sheet := &m.sheets[m.currentSheet]
+	sheet.Formats = append([]models.FormatRule{rule}, sheet.Formats...)
+	m.formatCursor = 0
+	m.formatsOff = false
+	m.refreshFormatter()
+	m.ruleInput.SetValue("")
+	m.status = models.StatusMsg{Message: "Added rule " + rule.Rule, Type: models.StatusSuccess}
+	m.saveSessionFormats()
+}
+
+// deleteFormat removes the selected rule. Rules read from the file stay,
+// as vex does not write conditional formats back.
+func (m *Model) deleteFormat() {
+	sheet := &m.sheets[m.currentSheet]
+	if m.formatCursor >= len(sheet.Formats) {
+		return
+	}
+	rule := sheet.Formats[m.formatCursor]
+	if rule.Rule == "" {
+		m.status = models.StatusMsg{Message: "Rules from the file cannot be deleted here", Type: models.StatusWarning}
+		return
+	}
+	sheet.Formats = append(sheet.Formats[:m.formatCursor:m.formatCursor], sheet.Formats[m.formatCursor+1:]...)
+	m.formatCursor = ui.Max(0, ui.Min(m.formatCursor, len(sheet.Formats)-1))
+	m.refreshFormatter()
+	m.status = models.StatusMsg{Message: "Deleted rule " + rule.Rule, Type: models.StatusInfo}
+	m.saveSessionFormats()
+}
+
+// refreshFormatter rebuilds the evaluator of the current sheet's conditional
+// formats, nil when they are hidden or the sheet has none. The evaluator
+// keeps the bounds it works out, so it lives until the sheet, its rules or
+// its values change.
+func (m *Model) refreshFormatter() {
+	m.formatter = nil
+	if !m.formatsOff {
+		m.formatter = condfmt.New(&m.sheets[m.currentSheet], m.cellValues(m.currentSheet))
+	}
+}
+
+// formatColor resolves a rule colour, a theme colour name or #RRGGBB
+func formatColor(c string) lipgloss.Color {
+	t := theme.GetCurrentTheme()
+	switch c {
+	case "error":
+		return t.Error
+	case "warning":
+		return t.Warning
+	case "success":
+		return t.Success
+	case "accent":
+		return t.Accent
+	case "primary":
+		return t.Primary
+	case "secondary":
+		return t.Secondary
+	}
+	return lipgloss.Color(c)
+}
+
+// renderFormatCell renders a cell styled by its conditional formats. A data
+// bar fills the share of the cell its value calls for.
+func (m *Model) renderFormatCell(text string, st condfmt.Style, cursorRow bool) string {
+	style := m.styles.Cell.Copy()
+	if cursorRow {
+		style = m.styles.RowHighlight.Copy()
+	}
+	if st.Fg != "" {
+		style = style.Foreground(formatColor(st.Fg))
+	}
+	if st.Bg != "" {
+		style = style.Background(formatColor(st.Bg))
+	}
+	if st.Bar == 0 {
+		return style.Render(text)
+	}
+
+	text += strings.Repeat(" ", ui.Max(0, ui.MinCellWidth-lipgloss.Width(text)))
+	runes := []rune(text)
+	n := ui.Min(len(runes), int(math.Round(st.Bar*float64(ui.MinCellWidth))))
+	bar := style.Copy().UnsetWidth().Background(lipgloss.Color(st.BarColor)).Foreground(lipgloss.Color(condfmt.Contrast(st.BarColor)))
+	return bar.Render(string(runes[:n])) + style.UnsetWidth().Render(string(runes[n:]))
+}
+
+// renderFormats renders the conditional format dialog
+func (m Model) renderFormats() string {
+	t := theme.GetCurrentTheme()
+	dim := lipgloss.NewStyle().Foreground(t.DimText)
+	text := lipgloss.NewStyle().Foreground(t.Text)
+	selected := lipgloss.NewStyle().Foreground(t.Accent).Bold(true)
+
+	sheet := m.sheets[m.currentSheet]
+	content := m.styles.ModalTitle.Render("🎨 Conditional formats of "+ui.Truncate(sheet.Name, 30)) + "\n\n"
+
+	rules := sheet.Formats
+	if len(rules) == 0 {
+		content += dim.Render("No rules yet") + "\n"
+	}
+	start := ui.Max(0, ui.Min(m.formatCursor-formatListHeight/2, len(rules)-formatListHeight))
+	end := ui.Min(len(rules), start+formatListHeight)
+	if start > 0 {
+		content += dim.Render(fmt.Sprintf("  ↑ %d more", start)) + "\n"
+	}
+	for i := start; i < end; i++ {
+		r := rules[i]
+		origin := "file"
+		if r.Rule != "" {
+			origin = "session"
+		}
+		sample := m.renderFormatCell("Sample", formatSample(r), false)
+		line := fmt.Sprintf("%-10s %s", ui.Truncate(condfmt.RangeLabel(r), 10), ui.Truncate(condfmt.Describe(r), 38))
+		line = text.Copy().Width(49).Render(line) + " " + sample + " " + dim.Render(origin)
+		if i == m.formatCursor && !m.formatEditing {
+			content += selected.Render("→ ") + line + "\n"
+		} else {
+			content += "  " + line + "\n"
+		}
+	}
+	if end < len(rules) {
+		content += dim.Render(fmt.Sprintf("  ↓ %d more", len(rules)-end)) + "\n"
+	}
+
+	content += "\n" + m.styles.ModalKey.Render("New rule: ") + m.ruleInput.View() + "\n"
+	content += dim.Render("<column> <condition> -> <colour> [on <colour>], colours "+strings.Join(condfmt.ThemeColors, " ")+" or #RRGGBB") + "\n"
+	if m.formatsOff {
+		content += lipgloss.NewStyle().Foreground(t.Warning).Render("Formats are hidden") + "\n"
+	}
+
+	var help []string
+	if m.formatEditing {
+		help = []string{"Enter add", "↑/↓ history", "Tab/Esc back to list"}
+	} else {
+		help = []string{"j/k select", "a add", "x delete", "t show/hide", "Esc close"}
+	}
+	content += "\n" + dim.Italic(true).Render(strings.Join(help, " • "))
+	return m.styles.Modal.Width(92).Render(content)
+}
+
+// formatSample returns the style a rule gives the cells it picks out, for
+// the sample shown next to it
+func formatSample(r models.FormatRule) condfmt.Style {
+	switch r.Kind {
+	case models.FormatColorScale:
+		last := r.Stops[len(r.Stops)-1].Color
+		return condfmt.Style{Fg: condfmt.Contrast(last), Bg: last}
+	case models.FormatDataBar:
+		return condfmt.Style{Bar: 0.6, BarColor: r.Fill}
+	}
+	return condfmt.Style{Fg: r.Fg, Bg: r.Fill}
+}
@@ -0,0 +1,308 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vex/internal/theme"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+const (
+	// maxHistoryEntries bounds the number of undoable actions
+	maxHistoryEntries = 200
+	// maxHistoryCost bounds the number of cells retained across all actions
+	maxHistoryCost = 500000
+)
+
+// command is a reversible change to the workbook
+type command interface {
+	apply(m *Model)
+	revert(m *Model)
+	label() string
+	cost() int
+	sheets() []int // Sheets the command changes
+}
+
+// historyEntry is a command together with the time it was executed
+type historyEntry struct {
+	cmd  command
+	when time.Time
+}
+
+// history holds undo and redo stacks
+type history struct {
+	done   []historyEntry
+	undone []historyEntry
+	cost   int
+}
+
+// push records an executed command, discarding redo entries and the
+// oldest undo entries when the history grows beyond its limits. It
+// reports false if the command alone is too large to be kept.
+func (h *history) push(cmd command) bool {
+	for _, e := range h.undone {
+		h.cost -= e.cmd.cost()
+	}
+	h.undone = nil
+
+	if cmd.cost() > maxHistoryCost {
+		h.done = nil
+		h.cost = 0
+		return false
+	}
+
+	h.done = append(h.done, historyEntry{cmd: cmd, when: time.Now()})
+	h.cost += cmd.cost()
+	for len(h.done) > maxHistoryEntries || h.cost > maxHistoryCost {
+		h.cost -= h.done[0].cmd.cost()
+		h.done = h.done[1:]
+	}
+	return true
+}
+
+// execute applies a command and records it in the undo history
+func (m *Model) execute(cmd command) {
+	cmd.apply(m)
+	m.afterChange(cmd.sheets())
+	if !m.history.push(cmd) {
+		m.status = models.StatusMsg{
+			Message: cmd.label() + " (too large to undo)",
+			Type:    models.StatusWarning,
+		}
+		return
+	}
+	m.status = models.StatusMsg{Message: cmd.label(), Type: models.StatusSuccess}
+}
+
+// undo reverts the most recent command
+func (m *Model) undo() bool {
+	h := &m.history
+	if len(h.done) == 0 {
+		m.status = models.StatusMsg{Message: "Nothing to undo", Type: models.StatusWarning}
+		return false
+	}
+	entry := h.done[len(h.done)-1]
+	h.done = h.done[:len(h.done)-1]
+	entry.cmd.revert(m)
+	m.afterChange(entry.cmd.sheets())
+	h.undone = append(h.undone, entry)
+	m.status = models.StatusMsg{Message: "Undo: " + entry.cmd.label(), Type: models.StatusInfo}
+	return true
+}
+
+// redo re-applies the most recently undone command
+func (m *Model) redo() bool {
+	h := &m.history
+	if len(h.undone) == 0 {
+		m.status = models.StatusMsg{Message: "Nothing to redo", Type: models.StatusWarning}
+		return false
+	}
+	entry := h.undone[len(h.undone)-1]
+	h.undone = h.undone[:len(h.undone)-1]
+	entry.cmd.apply(m)
+	m.afterChange(entry.cmd.sheets())
+	h.done = append(h.done, entry)
+	m.status = models.StatusMsg{Message: "Redo: " + entry.cmd.label(), Type: models.StatusInfo}
+	return true
+}
+
+// afterChange brings what is worked out from the changed sheets up to date
+// once a command is applied or reverted. The active search is repeated in
+// the background, as a large workbook can take a while to search.
+func (m *Model) afterChange(sheets []int) {
+	current := false
+	for _, s := range sheets {
+		m.refreshFilter(s)
+		if s == m.dupSheet {
+			m.refreshDuplicates()
+		}
+		if s == m.outlierSheet {
+			m.refreshOutliers()
+		}
+		if m.searchQuery != "" && (m.searchAll || s == m.currentSheet) {
+			m.searchStale = true
+		}
+		current = current || s == m.currentSheet
+	}
+	if current {
+		m.snapToShownRow()
+		m.refreshFormatter()
+		m.updateSelectionStats()
+	}
+}
+
+// rollbackTo undoes commands until only the first n remain applied
+func (m *Model) rollbackTo(n int) {
+	count := 0
+	for len(m.history.done) > n && m.undo() {
+		count++
+	}
+	if count > 0 {
+		m.status = models.StatusMsg{
+			Message: fmt.Sprintf("Rolled back %d action(s)", count),
+			Type:    models.StatusSuccess,
+		}
+	}
+}
+
+// cellChange records the state of a single cell before and after a change
+type cellChange struct {
+	row    int
+	col    int
+	before models.Cell
+	after  models.Cell
+}
+
+// cellEdit sets a batch of cells on one sheet
+type cellEdit struct {
+	sheet   int
+	name    string
+	changes []cellChange
+	maxRows int // Sheet size before the edit, restored on undo
+	maxCols int
+}
+
+func (c *cellEdit) apply(m *Model) {
+	sheet := &m.sheets[c.sheet]
+	c.maxRows, c.maxCols = sheet.MaxRows, sheet.MaxCols
+	for _, ch := range c.changes {
+		*sheet.EnsureCell(ch.row, ch.col) = ch.after
+	}
+	sheet.Dirty = true
+	c.focus(m)
+}
+
+func (c *cellEdit) revert(m *Model) {
+	sheet := &m.sheets[c.sheet]
+	for i := len(c.changes) - 1; i >= 0; i-- {
+		ch := c.changes[i]
+		*sheet.EnsureCell(ch.row, ch.col) = ch.before
+	}
+	sheet.Truncate(c.maxRows, c.maxCols)
+	sheet.Dirty = true
+	c.focus(m)
+}
+
+func (c *cellEdit) label() string { return c.name }
+
+func (c *cellEdit) cost() int { return len(c.changes) }
+
+func (c *cellEdit) sheets() []int { return []int{c.sheet} }
+
+// focus moves the cursor to the first changed cell
+func (c *cellEdit) focus(m *Model) {
+	if len(c.changes) > 0 {
+		m.moveTo(position{sheet: c.sheet, row: c.changes[0].row, col: c.changes[0].col})
+	}
+}
+
+// batchEdit groups cell edits on several sheets into one action
+type batchEdit struct {
+	name  string
+	edits []*cellEdit
+}
+
+func (b *batchEdit) apply(m *Model) {
+	for _, e := range b.edits {
+		e.apply(m)
+	}
+	b.focus(m)
+}
+
+func (b *batchEdit) revert(m *Model) {
+	for i := len(b.edits) - 1; i >= 0; i-- {
+		b.edits[i].revert(m)
+	}
+	b.focus(m)
+}
+
+func (b *batchEdit) label() string { return b.name }
+
+func (b *batchEdit) cost() int {
+	total := 0
+	for _, e := range b.edits {
+		total += e.cost()
+	}
+	return total
+}
+
+func (b *batchEdit) sheets() []int {
+	sheets := make([]int, len(b.edits))
+	for i, e := range b.edits {
+		sheets[i] = e.sheet
+	}
+	return sheets
+}
+
+// focus moves the cursor to the first changed cell of the first sheet
+func (b *batchEdit) focus(m *Model) {
+	if len(b.edits) > 0 {
+		b.edits[0].focus(m)
+	}
+}
+
+// updateHistory handles the history panel
+func (m Model) updateHistory(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Entry 0 is the most recent action, the last entry is the start of history
+	entries := len(m.history.done) + 1
+
+	switch msg.String() {
+	case "esc", "q", "U":
+		m.mode = models.ModeNormal
+	case "up", "k":
+		if m.historyCursor > 0 {
+			m.historyCursor--
+		}
+	case "down", "j":
+		if m.historyCursor < entries-1 {
+			m.historyCursor++
+		}
+	case "enter":
+		m.rollbackTo(len(m.history.done) - m.historyCursor)
+		m.mode = models.ModeNormal
+	}
+	return m, nil
+}
+
+// renderHistory renders the history panel
+func (m Model) renderHistory() string {
+	t := theme.GetCurrentTheme()
+	dim := lipgloss.NewStyle().Foreground(t.DimText)
+	text := lipgloss.NewStyle().Foreground(t.Text)
+	selected := lipgloss.NewStyle().Foreground(t.Accent).Bold(true)
+
+	content := m.styles.ModalTitle.Render("🕘 History") + "\n\n"
+
+	// Undone actions that can still be redone, the next redo last
+	for i := ui.Max(0, len(m.history.undone)-5); i < len(m.history.undone); i++ {
+		e := m.history.undone[i]
+		content += dim.Render(fmt.Sprintf("  ↷ %s  %s", e.when.Format("15:04:05"), e.cmd.label())) + "\n"
+	}
+
+	const maxShown = 15
+	start := ui.Max(0, m.historyCursor-maxShown+1)
+	done := m.history.done
+	for i := start; i <= len(done) && i < start+maxShown; i++ {
+		line := "Start of history"
+		if i < len(done) {
+			e := done[len(done)-1-i]
+			line = fmt.Sprintf("%s  %s", e.when.Format("15:04:05"), e.cmd.label())
+		}
+		if i == m.historyCursor {
+			content += selected.Render("→ "+line) + "\n"
+		} else {
+			content += text.Render("  "+line) + "\n"
+		}
+	}
+
+	content += "\n" + dim.Italic(true).Render(strings.Join([]string{
+		"↑/↓ select", "Enter roll back to here", "Esc close",
+	}, " • "))
+
+	return m.styles.Modal.Width(64).Render(content)
+}
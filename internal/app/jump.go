@@ -0,0 +1,323 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+// maxJumpHistory bounds the number of remembered jump locations
+const maxJumpHistory = 100
+
+var (
+	relativeRefPattern = regexp.MustCompile(`^([+-])(\d+)$`)
+	r1c1RefPattern     = regexp.MustCompile(`^R(\d+)C(\d+)$`)
+	a1RefPattern       = regexp.MustCompile(`^([A-Z]+)(\d+)$`)
+	colRefPattern      = regexp.MustCompile(`^[A-Z]+$`)
+	rowRefPattern      = regexp.MustCompile(`^\d+$`)
+	rowColRefPattern   = regexp.MustCompile(`^(\d+)\s*,\s*(\d+)$`)
+)
+
+// position is a cursor location recorded in the jump history
+type position struct {
+	sheet int
+	row   int
+	col   int
+}
+
+// jumpTarget is a parsed jump dialog reference. A row or column of -1
+// keeps the current cursor row or column.
+type jumpTarget struct {
+	sheet   int
+	row     int
+	col     int
+	endRow  int
+	endCol  int
+	isRange bool
+}
+
+// parseJumpTarget parses jump dialog input such as A100, Sheet2!B7,
+// 'My Sheet'!A1:C20, R1C1, +50, D, 500 or 10,5
+func (m *Model) parseJumpTarget(input string) (jumpTarget, error) {
+	target := jumpTarget{sheet: m.currentSheet, row: -1, col: -1}
+
+	sheetName, ref, hasSheet, err := splitSheetRef(strings.TrimSpace(input))
+	if err != nil {
+		return target, err
+	}
+	if hasSheet {
+		idx := m.findSheet(sheetName)
+		if idx < 0 {
+			return target, fmt.Errorf("unknown sheet '%s'", sheetName)
+		}
+		target.sheet = idx
+	}
+
+	sheet := m.sheets[target.sheet]
+	ref = strings.ToUpper(strings.TrimSpace(ref))
+
+	switch {
+	case ref == "" && hasSheet:
+		target.row, target.col = 0, 0
+
+	case relativeRefPattern.MatchString(ref):
+		parts := relativeRefPattern.FindStringSubmatch(ref)
+		n, _ := strconv.Atoi(parts[2])
+		if parts[1] == "-" {
+			n = -n
+		}
+		target.row = clamp(m.cursorRow+n, 0, sheet.MaxRows-1)
+
+	case r1c1RefPattern.MatchString(ref):
+		parts := r1c1RefPattern.FindStringSubmatch(ref)
+		if target.row, err = oneBased(parts[1]); err != nil {
+			return target, err
+		}
+		if target.col, err = oneBased(parts[2]); err != nil {
+			return target, err
+		}
+
+	case strings.Contains(ref, ":"):
+		bounds := strings.SplitN(ref, ":", 2)
+		startRow, startCol, ok1 := parseA1(bounds[0])
+		endRow, endCol, ok2 := parseA1(bounds[1])
+		if !ok1 || !ok2 {
+			return target, fmt.Errorf("invalid range '%s'", ref)
+		}
+		target.row, target.col = ui.Min(startRow, endRow), ui.Min(startCol, endCol)
+		target.endRow, target.endCol = ui.Max(startRow, endRow), ui.Max(startCol, endCol)
+		target.isRange = true
+		if target.endRow >= sheet.MaxRows || target.endCol >= sheet.MaxCols {
+			return target, fmt.Errorf("range '%s' is outside the sheet", ref)
+		}
+
+	case a1RefPattern.MatchString(ref):
+		var ok bool
+		if target.row, target.col, ok = parseA1(ref); !ok {
+			return target, errZeroIndex
+		}
+
+	case colRefPattern.MatchString(ref):
+		target.col = ui.LetterToColIndex(ref)
+
+	case rowRefPattern.MatchString(ref):
+		if target.row, err = oneBased(ref); err != nil {
+			return target, err
+		}
+
+	case rowColRefPattern.MatchString(ref):
+		parts := rowColRefPattern.FindStringSubmatch(ref)
+		if target.row, err = oneBased(parts[1]); err != nil {
+			return target, err
+		}
+		if target.col, err = oneBased(parts[2]); err != nil {
+			return target, err
+		}
+
+	default:
+		return target, fmt.Errorf("invalid cell reference")
+	}
+
+	if target.row >= sheet.MaxRows || target.col >= sheet.MaxCols || target.row < -1 || target.col < -1 {
+		return target, fmt.Errorf("reference is outside the sheet")
+	}
+
+	return target, nil
+}
+
+// errZeroIndex rejects a row or column number below 1
+var errZeroIndex = errors.New("rows and columns are numbered from 1")
+
+// oneBased converts a row or column number typed in the jump dialog to a
+// 0-based index
+func oneBased(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, errZeroIndex
+	}
+	return n - 1, nil
+}
+
+// splitSheetRef splits "Sheet!A1" or "'My Sheet'!A1" into sheet and cell parts
+func splitSheetRef(input string) (sheet, ref string, hasSheet bool, err error) {
+	if strings.HasPrefix(input, "'") {
+		var name strings.Builder
+		for i := 1; i < len(input); i++ {
+			if input[i] != '\'' {
+				name.WriteByte(input[i])
+				continue
+			}
+			// A doubled quote is an escaped quote inside the name
+			if i+1 < len(input) && input[i+1] == '\'' {
+				name.WriteByte('\'')
+				i++
+				continue
+			}
+			rest := input[i+1:]
+			if rest != "" && !strings.HasPrefix(rest, "!") {
+				return "", "", false, fmt.Errorf("expected ! after sheet name")
+			}
+			return name.String(), strings.TrimPrefix(rest, "!"), true, nil
+		}
+		return "", "", false, fmt.Errorf("unterminated sheet name")
+	}
+
+	if idx := strings.LastIndex(input, "!"); idx >= 0 {
+		return input[:idx], input[idx+1:], true, nil
+	}
+	return "", input, false, nil
+}
+
+// parseA1 parses an A1-style reference into 0-indexed row and column
+func parseA1(ref string) (row, col int, ok bool) {
+	parts := a1RefPattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(ref)))
+	if parts == nil {
+		return 0, 0, false
+	}
+	r, err := strconv.Atoi(parts[2])
+	if err != nil || r < 1 {
+		return 0, 0, false
+	}
+	return r - 1, ui.LetterToColIndex(parts[1]), true
+}
+
+// findSheet returns the index of the sheet with the given name, or -1
+func (m *Model) findSheet(name string) int {
+	for i, sheet := range m.sheets {
+		if sheet.Name == name {
+			return i
+		}
+	}
+	for i, sheet := range m.sheets {
+		if strings.EqualFold(sheet.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// jumpToCell jumps to a specific cell based on user input
+func (m *Model) jumpToCell(input string) {
+	target, err := m.parseJumpTarget(input)
+	if err != nil {
+		m.status = models.StatusMsg{Message: "Invalid reference: " + err.Error(), Type: models.StatusError}
+		return
+	}
+
+	m.pushJumpHistory()
+	m.moveTo(position{sheet: target.sheet, row: target.row, col: target.col})
+
+	if target.isRange {
+		m.selectStart = [2]int{target.row, target.col}
+		m.selectEnd = [2]int{target.endRow, target.endCol}
+		m.isSelecting = true
+		m.updateSelectionStats()
+		m.status = models.StatusMsg{
+			Message: fmt.Sprintf("→ %s:%s", m.cellLabel(target.row, target.col), ui.CellRef(target.endRow, target.endCol)),
+			Type:    models.StatusSuccess,
+		}
+		return
+	}
+
+	m.status = models.StatusMsg{
+		Message: "→ " + m.cellLabel(m.cursorRow, m.cursorCol),
+		Type:    models.StatusSuccess,
+	}
+}
+
+// moveTo moves the cursor to a position, switching sheets if needed.
+// A row or column of -1 keeps the current value.
+func (m *Model) moveTo(pos position) {
+	if pos.sheet != m.currentSheet {
+		m.currentSheet = pos.sheet
+		m.isSelecting = false
+		m.refreshFormatter()
+	}
+	sheet := m.sheets[m.currentSheet]
+
+	if pos.row >= 0 {
+		m.cursorRow = pos.row
+	}
+	if pos.col >= 0 {
+		m.cursorCol = pos.col
+	}
+	m.cursorRow = clamp(m.cursorRow, 0, ui.Max(0, sheet.MaxRows-1))
+	m.cursorCol = clamp(m.cursorCol, 0, ui.Max(0, sheet.MaxCols-1))
+	m.snapToShownRow()
+	m.centerView()
+}
+
+// cellLabel formats a cell reference, qualified with the sheet name when
+// the workbook has more than one sheet
+func (m *Model) cellLabel(row, col int) string {
+	return m.sheetCellLabel(m.currentSheet, row, col)
+}
+
+// sheetCellLabel formats a cell reference on the given sheet
+func (m *Model) sheetCellLabel(sheet, row, col int) string {
+	ref := ui.CellRef(row, col)
+	if len(m.sheets) > 1 {
+		name := m.sheets[sheet].Name
+		if strings.ContainsAny(name, " !'") {
+			name = "'" + strings.ReplaceAll(name, "'", "''") + "'"
+		}
+		ref = name + "!" + ref
+	}
+	return ref
+}
+
+// currentPosition returns the current cursor location
+func (m *Model) currentPosition() position {
+	return position{sheet: m.currentSheet, row: m.cursorRow, col: m.cursorCol}
+}
+
+// pushJumpHistory records the current location before a jump and
+// discards any forward history
+func (m *Model) pushJumpHistory() {
+	m.jumpBack = append(m.jumpBack, m.currentPosition())
+	if len(m.jumpBack) > maxJumpHistory {
+		m.jumpBack = m.jumpBack[len(m.jumpBack)-maxJumpHistory:]
+	}
+	m.jumpForward = nil
+}
+
+// jumpHistoryBack returns to the location before the last jump
+func (m *Model) jumpHistoryBack() {
+	if len(m.jumpBack) == 0 {
+		m.status = models.StatusMsg{Message: "No earlier jump", Type: models.StatusWarning}
+		return
+	}
+	pos := m.jumpBack[len(m.jumpBack)-1]
+	m.jumpBack = m.jumpBack[:len(m.jumpBack)-1]
+	m.jumpForward = append(m.jumpForward, m.currentPosition())
+	m.moveTo(pos)
+	m.status = models.StatusMsg{Message: "← " + m.cellLabel(m.cursorRow, m.cursorCol), Type: models.StatusInfo}
+}
+
+// jumpHistoryForward re-applies a jump undone with jumpHistoryBack
+func (m *Model) jumpHistoryForward() {
+	if len(m.jumpForward) == 0 {
+		m.status = models.StatusMsg{Message: "No later jump", Type: models.StatusWarning}
+		return
+	}
+	pos := m.jumpForward[len(m.jumpForward)-1]
+	m.jumpForward = m.jumpForward[:len(m.jumpForward)-1]
+	m.jumpBack = append(m.jumpBack, m.currentPosition())
+	m.moveTo(pos)
+	m.status = models.StatusMsg{Message: "→ " + m.cellLabel(m.cursorRow, m.cursorCol), Type: models.StatusInfo}
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
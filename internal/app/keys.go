@@ -20,12 +20,44 @@ type KeyMap struct {
 	NextResult  key.Binding
 	PrevResult  key.Binding
 	ClearSearch key.Binding
+	SearchList  key.Binding
+	Replace     key.Binding
 	Detail      key.Binding
 	Jump        key.Binding
+	Finder      key.Binding
+	JumpBack    key.Binding
+	JumpForward key.Binding
 	ToggleForm  key.Binding
 	Copy        key.Binding
 	CopyRow     key.Binding
+	Paste       key.Binding
+	CopyAs      key.Binding
 	Export      key.Binding
+	Edit        key.Binding
+	Save        key.Binding
+	Undo        key.Binding
+	Redo        key.Binding
+	History     key.Binding
+	InsertRow   key.Binding
+	InsertAbove key.Binding
+	DeleteRow   key.Binding
+	InsertCol   key.Binding
+	DeleteCol   key.Binding
+	MoveRowUp   key.Binding
+	MoveRowDown key.Binding
+	DupRow      key.Binding
+	SortAsc     key.Binding
+	SortDesc    key.Binding
+	SortBy      key.Binding
+	Filter      key.Binding
+	Stats       key.Binding
+	Pivot       key.Binding
+	Query       key.Binding
+	Computed    key.Binding
+	Duplicates  key.Binding
+	Profile     key.Binding
+	Outliers    key.Binding
+	Formats     key.Binding
 	Theme       key.Binding
 	Help        key.Binding
 	Quit        key.Binding
@@ -44,9 +76,13 @@ func (k KeyMap) FullHelp() [][]key.Binding {
 		{k.Up, k.Down, k.Left, k.Right},
 		{k.PageUp, k.PageDown, k.FirstCol, k.LastCol},
 		{k.Home, k.End, k.NextSheet, k.PrevSheet},
-		{k.Search, k.NextResult, k.PrevResult, k.ClearSearch},
-		{k.Detail, k.Jump, k.ToggleForm},
-		{k.Copy, k.CopyRow, k.Export, k.Theme},
+		{k.Search, k.NextResult, k.PrevResult, k.SearchList, k.ClearSearch, k.Replace},
+		{k.Detail, k.Jump, k.Finder, k.JumpBack, k.JumpForward, k.ToggleForm},
+		{k.Copy, k.CopyRow, k.CopyAs, k.Paste, k.Export, k.Theme},
+		{k.Edit, k.Save, k.Undo, k.Redo, k.History},
+		{k.InsertRow, k.InsertAbove, k.DeleteRow, k.DupRow, k.Duplicates},
+		{k.InsertCol, k.DeleteCol, k.MoveRowUp, k.MoveRowDown, k.Computed},
+		{k.SortAsc, k.SortDesc, k.SortBy, k.Filter, k.Stats, k.Profile, k.Outliers, k.Formats, k.Pivot, k.Query},
 		{k.Visualize, k.SelectRange, k.Help, k.Quit},
 	}
 }
@@ -70,12 +106,44 @@ func DefaultKeyMap() KeyMap {
 		NextResult:  key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "next")),
 		PrevResult:  key.NewBinding(key.WithKeys("N"), key.WithHelp("N", "prev")),
 		ClearSearch: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "clear")),
+		SearchList:  key.NewBinding(key.WithKeys("L"), key.WithHelp("L", "results list")),
+		Replace:     key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "replace")),
 		Detail:      key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "detail")),
 		Jump:        key.NewBinding(key.WithKeys("ctrl+g"), key.WithHelp("^g", "jump")),
+		Finder:      key.NewBinding(key.WithKeys("ctrl+p"), key.WithHelp("^p", "fuzzy find")),
+		JumpBack:    key.NewBinding(key.WithKeys("["), key.WithHelp("[", "jump back")),
+		JumpForward: key.NewBinding(key.WithKeys("]"), key.WithHelp("]", "jump fwd")),
 		ToggleForm:  key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "formulas")),
 		Copy:        key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "copy")),
 		CopyRow:     key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "copy row")),
+		CopyAs:      key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy as…")),
+		Paste:       key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "paste")),
 		Export:      key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "export")),
+		Edit:        key.NewBinding(key.WithKeys("i", "f2"), key.WithHelp("i/f2", "edit cell")),
+		Save:        key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("^s", "save")),
+		Undo:        key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "undo")),
+		Redo:        key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("^r", "redo")),
+		History:     key.NewBinding(key.WithKeys("U"), key.WithHelp("U", "history")),
+		InsertRow:   key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "insert row below")),
+		InsertAbove: key.NewBinding(key.WithKeys("O"), key.WithHelp("O", "insert row above")),
+		DeleteRow:   key.NewBinding(key.WithKeys("D"), key.WithHelp("D", "delete row")),
+		InsertCol:   key.NewBinding(key.WithKeys("I"), key.WithHelp("I", "insert column")),
+		DeleteCol:   key.NewBinding(key.WithKeys("X"), key.WithHelp("X", "delete column")),
+		MoveRowUp:   key.NewBinding(key.WithKeys("K", "alt+up"), key.WithHelp("K", "move row up")),
+		MoveRowDown: key.NewBinding(key.WithKeys("J", "alt+down"), key.WithHelp("J", "move row down")),
+		DupRow:      key.NewBinding(key.WithKeys("Y"), key.WithHelp("Y", "duplicate row")),
+		SortAsc:     key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "sort asc")),
+		SortDesc:    key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "sort desc")),
+		SortBy:      key.NewBinding(key.WithKeys("alt+s"), key.WithHelp("⌥s", "sort by…")),
+		Filter:      key.NewBinding(key.WithKeys("F"), key.WithHelp("F", "filter column")),
+		Stats:       key.NewBinding(key.WithKeys("#"), key.WithHelp("#", "column stats")),
+		Pivot:       key.NewBinding(key.WithKeys("P"), key.WithHelp("P", "pivot table")),
+		Query:       key.NewBinding(key.WithKeys(":"), key.WithHelp(":", "sql query")),
+		Computed:    key.NewBinding(key.WithKeys("="), key.WithHelp("=", "computed column")),
+		Duplicates:  key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "duplicates")),
+		Profile:     key.NewBinding(key.WithKeys("alt+p"), key.WithHelp("⌥p", "profile")),
+		Outliers:    key.NewBinding(key.WithKeys("!"), key.WithHelp("!", "outliers")),
+		Formats:     key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("^f", "cond. formats")),
 		Theme:       key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "theme")),
 		Help:        key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
 		Quit:        key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
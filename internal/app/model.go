@@ -1,8 +1,20 @@
 package app
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/vex/internal/condfmt"
+	"github.com/vex/internal/config"
+	"github.com/vex/internal/dupes"
+	"github.com/vex/internal/fuzzy"
+	"github.com/vex/internal/loader"
+	"github.com/vex/internal/outliers"
+	"github.com/vex/internal/pivot"
+	"github.com/vex/internal/profile"
+	"github.com/vex/internal/stats"
 	"github.com/vex/internal/theme"
 	"github.com/vex/internal/ui"
 	"github.com/vex/pkg/models"
@@ -22,9 +34,20 @@ type Model struct {
 	searchInput   textinput.Model
 	jumpInput     textinput.Model
 	exportInput   textinput.Model
+	editInput     textinput.Model
 	searchQuery   string
-	searchResults []models.Cell
+	searchResults []searchResult
 	searchIndex   int
+	searchOpts    loader.SearchOptions
+	searchAll     bool // Search every sheet instead of the current one
+	searchRows    bool // Results are whole rows matched by a column query
+	searchHits    map[position]bool
+	searchMatcher *loader.Matcher
+	searchSeq     int // Bumped on every live search keystroke
+	searchCancel  context.CancelFunc
+	searchOrigin  position // Cursor when the search bar was opened
+	searchStale   bool     // The searched sheets changed since the search ran
+	resultsCursor int
 	showFormulas  bool
 	status        models.StatusMsg
 	help          help.Model
@@ -38,6 +61,104 @@ type Model struct {
 	selectStart   [2]int // [row, col]
 	selectEnd     [2]int // [row, col]
 	isSelecting   bool
+	selectAgg     stats.Aggregate // Summary of the selected cells
+
+	// Undo history
+	history       history
+	historyCursor int
+	saves         int // Times the workbook was saved, each emptying the save journals
+
+	// Copy format modal
+	copyFormat int
+
+	// Paste preview
+	pasteData       [][]string
+	pasteValuesOnly bool
+	pasteTranspose  bool
+
+	// Find and replace
+	replaceFind    textinput.Model
+	replaceWith    textinput.Model
+	replaceOpts    loader.SearchOptions
+	replaceScope   searchScope
+	replaceMatches []replaceMatch
+	replaceIndex   int
+	replaceReview  bool
+
+	// Fuzzy finder
+	finderInput   textinput.Model
+	finderItems   []finderItem
+	finderMatches []fuzzy.Match
+	finderCursor  int
+
+	// Sorting
+	sortKeys   []sortKey
+	sortCursor int
+	sortHeader bool // Keep the header row and the rows above it in place when sorting
+
+	// Column statistics
+	statsCol     int
+	statsTotal   int // Values being summarised
+	statsSummary *stats.Summary
+	statsSeq     int // Bumped whenever the panel closes or changes column
+	statsCancel  context.CancelFunc
+
+	// Pivot builder
+	pivotSheet  int
+	pivotRows   []int
+	pivotCols   []int
+	pivotValue  int
+	pivotAgg    pivot.Aggregate
+	pivotCursor int
+
+	// SQL query prompt
+	queryInput textinput.Model
+
+	// Computed column prompt
+	exprInput textinput.Model
+
+	// Duplicate rows
+	dupSheet      int // Sheet searched for duplicates, -1 for none
+	dupCols       []int
+	dupIgnoreCase bool
+	dupGroups     []dupes.Group
+	dupRows       map[int]bool
+	dupCursor     int
+	dupResults    bool // The dialog lists groups rather than key columns
+
+	// Profile report
+	profileReport *profile.Report
+	profileCursor int
+
+	// Outliers
+	outlierSheet int // Sheet whose outliers are highlighted, -1 for none
+	outlierOpts  outliers.Options
+	outlierCells []outliers.Cell
+	outlierHits  map[position]int
+	outlierNav   bool // n/N step through outliers rather than search results
+
+	// Conditional formats
+	formatCursor  int
+	formatEditing bool               // The new rule field has focus
+	formatsOff    bool               // Draw cells without their conditional formats
+	formatter     *condfmt.Formatter // Evaluates the current sheet's formats, nil for none
+	ruleInput     textinput.Model
+	sessions      config.Sessions
+
+	// Autofilter dialog
+	filterCol     int
+	filterItems   []filterItem
+	filterCursor  int
+	filterCond    textinput.Model
+	filterEditing bool // The condition field has focus
+
+	// Persistent prompt history
+	inputHistory config.History
+	promptHist   promptHistory
+
+	// Jump history
+	jumpBack    []position
+	jumpForward []position
 }
 
 // NewModel creates a new application model
@@ -58,7 +179,7 @@ func NewModel(filename string, sheets []models.Sheet, themeName string) Model {
 	searchInput.Width = 50
 
 	jumpInput := textinput.New()
-	jumpInput.Placeholder = "A100, 500, or 10,5"
+	jumpInput.Placeholder = "A100, Sheet2!B7, R1C1, +50, or D"
 	jumpInput.CharLimit = 50
 	jumpInput.Width = 30
 
@@ -67,12 +188,74 @@ func NewModel(filename string, sheets []models.Sheet, themeName string) Model {
 	exportInput.CharLimit = 100
 	exportInput.Width = 40
 
-	return Model{
+	editInput := textinput.New()
+	editInput.Placeholder = "value or =formula"
+	editInput.CharLimit = 32767
+	editInput.Width = 80
+
+	replaceFind := textinput.New()
+	replaceFind.Placeholder = "text or regex"
+	replaceFind.CharLimit = 200
+	replaceFind.Width = 60
+
+	replaceWith := textinput.New()
+	replaceWith.Placeholder = "replacement"
+	replaceWith.CharLimit = 200
+	replaceWith.Width = 60
+
+	finderInput := textinput.New()
+	finderInput.Placeholder = "sheet, column or value"
+	finderInput.CharLimit = 100
+	finderInput.Width = 60
+
+	filterCond := textinput.New()
+	filterCond.Prompt = ""
+	filterCond.Placeholder = "no condition"
+	filterCond.CharLimit = 100
+	filterCond.Width = 40
+
+	queryInput := textinput.New()
+	queryInput.Prompt = ""
+	queryInput.Placeholder = "SELECT Region, SUM(Revenue) FROM Sheet1 GROUP BY Region"
+	queryInput.Width = 100
+
+	exprInput := textinput.New()
+	exprInput.Prompt = ""
+	exprInput.Placeholder = "Price * Quantity AS Total"
+	exprInput.Width = 100
+
+	ruleInput := textinput.New()
+	ruleInput.Prompt = ""
+	ruleInput.Placeholder = "Profit Margin < 30% -> error"
+	ruleInput.CharLimit = 200
+	ruleInput.Width = 60
+
+	// History and sessions are a convenience; start empty if unreadable
+	inputHistory, _ := config.LoadHistory()
+	sessions, _ := config.LoadSessions()
+
+	m := Model{
 		sheets:       sheets,
 		currentSheet: 0,
 		searchInput:  searchInput,
 		jumpInput:    jumpInput,
 		exportInput:  exportInput,
+		editInput:    editInput,
+		searchOpts:   loader.SearchOptions{Formulas: true},
+		replaceFind:  replaceFind,
+		replaceWith:  replaceWith,
+		finderInput:  finderInput,
+		filterCond:   filterCond,
+		queryInput:   queryInput,
+		exprInput:    exprInput,
+		ruleInput:    ruleInput,
+		inputHistory: inputHistory,
+		sessions:     sessions,
+		promptHist:   promptHistory{pos: -1},
+		sortHeader:   true,
+		pivotSheet:   -1,
+		dupSheet:     -1,
+		outlierSheet: -1,
 		help:         help.New(),
 		keys:         DefaultKeyMap(),
 		filename:     filename,
@@ -83,6 +266,29 @@ func NewModel(filename string, sheets []models.Sheet, themeName string) Model {
 			Type:    models.StatusInfo,
 		},
 	}
+	m.loadSessionFormats()
+	m.refreshFormatter()
+	m.reportLoadWarnings()
+	return m
+}
+
+// reportLoadWarnings shows what loading left out of the sheets, such as
+// unreadable conditional formats, in place of the ready message
+func (m *Model) reportLoadWarnings() {
+	var warnings []string
+	for _, sheet := range m.sheets {
+		for _, w := range sheet.Warnings {
+			warnings = append(warnings, sheet.Name+": skipped "+w)
+		}
+	}
+	if len(warnings) == 0 {
+		return
+	}
+	message := warnings[0]
+	if len(warnings) > 1 {
+		message += fmt.Sprintf(" (and %d more)", len(warnings)-1)
+	}
+	m.status = models.StatusMsg{Message: message, Type: models.StatusWarning}
 }
 
 // GetThemeNames returns available theme names
@@ -103,11 +309,11 @@ func (m *Model) adjustViewport() {
 	visibleRows := ui.Max(1, m.height-9)
 	visibleCols := ui.Max(1, (m.width-8)/(ui.MinCellWidth+2))
 
-	// Adjust vertical
+	// Adjust vertical, counting only rows the autofilter shows
 	if m.cursorRow < m.offsetRow {
 		m.offsetRow = m.cursorRow
-	} else if m.cursorRow >= m.offsetRow+visibleRows {
-		m.offsetRow = m.cursorRow - visibleRows + 1
+	} else if m.shownRows(m.offsetRow, m.cursorRow+1) > visibleRows {
+		m.offsetRow = m.stepRows(m.cursorRow, -(visibleRows - 1))
 	}
 
 	// Adjust horizontal
@@ -123,18 +329,16 @@ func (m *Model) centerView() {
 	visibleRows := ui.Max(1, m.height-9)
 	visibleCols := ui.Max(1, (m.width-8)/(ui.MinCellWidth+2))
 
-	m.offsetRow = ui.Max(0, m.cursorRow-visibleRows/2)
+	m.offsetRow = m.stepRows(m.cursorRow, -visibleRows/2)
 	m.offsetCol = ui.Max(0, m.cursorCol-visibleCols/2)
 }
 
 // isSearchMatch checks if a cell is a search match
 func (m *Model) isSearchMatch(row, col int) bool {
-	for _, result := range m.searchResults {
-		if result.Row == row && result.Col == col {
-			return true
-		}
+	if m.searchRows {
+		col = -1
 	}
-	return false
+	return m.searchHits[position{sheet: m.currentSheet, row: row, col: col}]
 }
 
 // applyTheme applies a new theme and reinitializes styles
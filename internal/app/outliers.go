@@ -0,0 +1,193 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vex/internal/outliers"
+	"github.com/vex/internal/theme"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+// outlierStep is how much +/- change the outlier threshold
+const outlierStep = 0.5
+
+// startOutliers opens the outlier settings
+func (m Model) startOutliers() (tea.Model, tea.Cmd) {
+	if m.outlierOpts.Threshold == 0 {
+		m.outlierOpts = outliers.Options{
+			Method:    outliers.MethodStdDev,
+			Threshold: outliers.MethodStdDev.DefaultThreshold(),
+			Patterns:  true,
+		}
+	}
+	m.mode = models.ModeOutliers
+	return m, nil
+}
+
+// updateOutliers handles the outlier settings
+func (m Model) updateOutliers(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.mode = models.ModeNormal
+	case "m":
+		m.outlierOpts.Method = (m.outlierOpts.Method + 1) % 2
+		m.outlierOpts.Threshold = m.outlierOpts.Method.DefaultThreshold()
+	case "+", "=":
+		m.outlierOpts.Threshold += outlierStep
+	case "-":
+		m.outlierOpts.Threshold = max(outlierStep, m.outlierOpts.Threshold-outlierStep)
+	case "p":
+		m.outlierOpts.Patterns = !m.outlierOpts.Patterns
+	case "X":
+		m.clearOutliers()
+		m.mode = models.ModeNormal
+		m.status = models.StatusMsg{Message: "Outlier highlights cleared", Type: models.StatusInfo}
+	case "enter":
+		m.mode = models.ModeNormal
+		m.findOutliers()
+	}
+	return m, nil
+}
+
+// findOutliers highlights the outliers of the current sheet and hands n/N
+// over to stepping through them
+func (m *Model) findOutliers() {
+	m.outlierSheet = m.currentSheet
+	m.outlierNav = true
+	m.refreshOutliers()
+
+	if len(m.outlierCells) == 0 {
+		m.status = models.StatusMsg{Message: "No outliers by " + m.outlierLabel(), Type: models.StatusSuccess}
+		return
+	}
+	patterns := 0
+	for _, c := range m.outlierCells {
+		if c.Kind == outliers.KindPattern {
+			patterns++
+		}
+	}
+	m.status = models.StatusMsg{
+		Message: fmt.Sprintf("%d outlier(s), %d breaking a pattern, by %s • n/N to step",
+			len(m.outlierCells)-patterns, patterns, m.outlierLabel()),
+		Type: models.StatusWarning,
+	}
+}
+
+// refreshOutliers repeats the outlier search after the sheet changes
+func (m *Model) refreshOutliers() {
+	if m.outlierSheet < 0 {
+		return
+	}
+	opts := m.outlierOpts
+	opts.HeaderRow = m.headerRowOf(m.outlierSheet)
+	f := m.sheets[m.outlierSheet].Filter
+	sheet := m.materialize(m.outlierSheet)
+	m.outlierCells = outliers.Find(&sheet, opts, func(row int) bool { return f != nil && f.IsHidden(row) })
+	m.outlierHits = make(map[position]int, len(m.outlierCells))
+	for i, c := range m.outlierCells {
+		m.outlierHits[position{sheet: m.outlierSheet, row: c.Row, col: c.Col}] = i
+	}
+}
+
+// clearOutliers forgets the outliers and their highlights
+func (m *Model) clearOutliers() {
+	m.outlierSheet = -1
+	m.outlierCells = nil
+	m.outlierHits = nil
+	m.outlierNav = false
+}
+
+// outlierLabel describes the outlier settings
+func (m *Model) outlierLabel() string {
+	label := fmt.Sprintf("%s %s", strconv.FormatFloat(m.outlierOpts.Threshold, 'f', -1, 64), m.outlierOpts.Method)
+	if m.outlierOpts.Patterns {
+		label += " and patterns"
+	}
+	return label
+}
+
+// isOutlier reports whether a cell of the current sheet is highlighted as
+// an outlier
+func (m *Model) isOutlier(row, col int) bool {
+	_, ok := m.outlierHits[position{sheet: m.currentSheet, row: row, col: col}]
+	return ok
+}
+
+// stepOutlier moves to the next outlier after the cursor in reading order,
+// or the previous one before it for negative dir, wrapping at the ends
+func (m *Model) stepOutlier(dir int) {
+	cells := m.outlierCells
+	idx := 0
+	if dir < 0 {
+		idx = len(cells) - 1
+	}
+	if m.currentSheet == m.outlierSheet {
+		before := func(c outliers.Cell) bool {
+			return c.Row < m.cursorRow || (c.Row == m.cursorRow && c.Col < m.cursorCol)
+		}
+		after := func(c outliers.Cell) bool {
+			return c.Row > m.cursorRow || (c.Row == m.cursorRow && c.Col > m.cursorCol)
+		}
+		if dir > 0 {
+			for i, c := range cells {
+				if after(c) {
+					idx = i
+					break
+				}
+			}
+		} else {
+			for i := len(cells) - 1; i >= 0; i-- {
+				if before(cells[i]) {
+					idx = i
+					break
+				}
+			}
+		}
+	}
+
+	c := cells[idx]
+	m.moveTo(position{sheet: m.outlierSheet, row: c.Row, col: c.Col})
+	m.status = models.StatusMsg{
+		Message: fmt.Sprintf("Outlier %d/%d: %s", idx+1, len(cells), c.Reason),
+		Type:    models.StatusWarning,
+	}
+}
+
+// renderOutliers renders the outlier settings
+func (m Model) renderOutliers() string {
+	t := theme.GetCurrentTheme()
+	dim := lipgloss.NewStyle().Foreground(t.DimText)
+	text := lipgloss.NewStyle().Foreground(t.Text)
+	checked := lipgloss.NewStyle().Foreground(t.Success).Bold(true)
+
+	opts := m.outlierOpts
+	content := m.styles.ModalTitle.Render("⚠ Outliers") + "\n\n"
+	field := func(label, value string) string {
+		return m.styles.ModalKey.Render(fmt.Sprintf("%-11s", label)) + m.styles.ModalValue.Render(value) + "\n"
+	}
+	content += field("Method", opts.Method.String())
+	content += field("Threshold", strconv.FormatFloat(opts.Threshold, 'f', -1, 64))
+	box := dim.Render("[ ] ")
+	if opts.Patterns {
+		box = checked.Render("[x] ")
+	}
+	content += m.styles.ModalKey.Render(fmt.Sprintf("%-11s", "Patterns")) + box + text.Render("flag text breaking its column's pattern") + "\n\n"
+
+	if opts.Method == outliers.MethodIQR {
+		content += dim.Render(fmt.Sprintf("Numbers below Q1 − %[1]g×IQR or above Q3 + %[1]g×IQR", opts.Threshold)) + "\n"
+	} else {
+		content += dim.Render(fmt.Sprintf("Numbers more than %g standard deviations from the mean", opts.Threshold)) + "\n"
+	}
+	if m.outlierSheet >= 0 {
+		content += text.Render(fmt.Sprintf("%d cell(s) highlighted in %s", len(m.outlierCells), ui.Truncate(m.sheets[m.outlierSheet].Name, 30))) + "\n"
+	}
+
+	help := []string{"m method", "+/- threshold", "p patterns", "Enter highlight", "X clear", "Esc cancel"}
+	content += "\n" + dim.Italic(true).Render(strings.Join(help, " • "))
+	return m.styles.Modal.Width(72).Render(content)
+}
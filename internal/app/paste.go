@@ -0,0 +1,216 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vex/internal/clipboard"
+	"github.com/vex/internal/loader"
+	"github.com/vex/internal/theme"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+// startPaste reads the clipboard and opens the paste preview
+func (m Model) startPaste() (tea.Model, tea.Cmd) {
+	text, err := clipboard.ReadAll()
+	if err != nil {
+		m.status = models.StatusMsg{Message: err.Error(), Type: models.StatusError}
+		return m, nil
+	}
+
+	data, err := loader.ParseTSV(text)
+	if err != nil {
+		m.status = models.StatusMsg{Message: err.Error(), Type: models.StatusError}
+		return m, nil
+	}
+	if len(data) == 0 {
+		m.status = models.StatusMsg{Message: "Clipboard is empty", Type: models.StatusWarning}
+		return m, nil
+	}
+
+	m.pasteData = data
+	m.pasteValuesOnly = false
+	m.pasteTranspose = false
+	m.mode = models.ModePaste
+	return m, nil
+}
+
+// updatePaste handles the paste preview
+func (m Model) updatePaste(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.mode = models.ModeNormal
+		m.pasteData = nil
+		m.status = models.StatusMsg{Message: "Paste cancelled", Type: models.StatusInfo}
+	case "v":
+		m.pasteValuesOnly = !m.pasteValuesOnly
+	case "t":
+		m.pasteTranspose = !m.pasteTranspose
+	case "enter", "p":
+		m.commitPaste()
+		m.mode = models.ModeNormal
+		m.pasteData = nil
+	}
+	return m, nil
+}
+
+// pasteBlock returns the clipboard block with the transpose option applied
+func (m *Model) pasteBlock() [][]string {
+	if !m.pasteTranspose {
+		return m.pasteData
+	}
+
+	width := 0
+	for _, row := range m.pasteData {
+		width = ui.Max(width, len(row))
+	}
+	block := make([][]string, width)
+	for c := range block {
+		block[c] = make([]string, len(m.pasteData))
+		for r, row := range m.pasteData {
+			if c < len(row) {
+				block[c][r] = row[c]
+			}
+		}
+	}
+	return block
+}
+
+// pasteSize returns the number of rows and columns the paste covers
+func pasteSize(block [][]string) (rows, cols int) {
+	for _, row := range block {
+		cols = ui.Max(cols, len(row))
+	}
+	return len(block), cols
+}
+
+// commitPaste writes the clipboard block at the cursor as one undoable
+// action. As in commitEdit, a new formula leaves its cell without a value.
+// Cells of computed columns follow their expression and are skipped.
+func (m *Model) commitPaste() {
+	block := m.pasteBlock()
+	sheet := m.sheets[m.currentSheet]
+
+	changes := make([]cellChange, 0, len(block))
+	skipped := 0
+	for r, row := range block {
+		for c, text := range row {
+			rowIdx, colIdx := m.cursorRow+r, m.cursorCol+c
+			if sheet.ComputedCell(rowIdx, colIdx) != nil {
+				skipped++
+				continue
+			}
+			before := sheet.CellAt(rowIdx, colIdx)
+			after := models.Cell{Value: text, Row: rowIdx, Col: colIdx}
+			if !m.pasteValuesOnly && strings.HasPrefix(text, "=") && len(text) > 1 {
+				after.Value = ""
+				after.Formula = text[1:]
+				if after.Formula == before.Formula {
+					after.Value = before.Value
+				}
+			}
+			if after.Value == before.Value && after.Formula == before.Formula {
+				continue
+			}
+			changes = append(changes, cellChange{row: rowIdx, col: colIdx, before: before, after: after})
+		}
+	}
+
+	if len(changes) == 0 && skipped > 0 {
+		m.status = models.StatusMsg{Message: "Computed cells follow their column's expression", Type: models.StatusWarning}
+		return
+	}
+	if len(changes) == 0 {
+		m.status = models.StatusMsg{Message: "Nothing changed", Type: models.StatusInfo}
+		return
+	}
+
+	rows, cols := pasteSize(block)
+	m.execute(&cellEdit{
+		sheet:   m.currentSheet,
+		name:    fmt.Sprintf("Paste %d×%d at %s", rows, cols, ui.CellRef(m.cursorRow, m.cursorCol)),
+		changes: changes,
+	})
+	if skipped > 0 {
+		m.status = models.StatusMsg{
+			Message: fmt.Sprintf("%s, skipped %d computed cell(s)", m.status.Message, skipped),
+			Type:    models.StatusWarning,
+		}
+	}
+}
+
+// renderPaste renders the paste preview modal
+func (m Model) renderPaste() string {
+	t := theme.GetCurrentTheme()
+	block := m.pasteBlock()
+	rows, cols := pasteSize(block)
+
+	content := m.styles.ModalTitle.Render("📋 Paste") + "\n\n"
+	content += m.styles.ModalKey.Render("Range: ") + m.styles.ModalValue.Render(fmt.Sprintf("%s:%s (%d×%d)",
+		ui.CellRef(m.cursorRow, m.cursorCol),
+		ui.CellRef(m.cursorRow+rows-1, m.cursorCol+ui.Max(cols, 1)-1),
+		rows, cols)) + "\n"
+
+	sheet := m.sheets[m.currentSheet]
+	overwrite, computed := 0, 0
+	for r, row := range block {
+		for c := range row {
+			switch {
+			case sheet.ComputedCell(m.cursorRow+r, m.cursorCol+c) != nil:
+				computed++
+			case sheet.CellAt(m.cursorRow+r, m.cursorCol+c).Value != "":
+				overwrite++
+			}
+		}
+	}
+	if overwrite > 0 {
+		content += lipgloss.NewStyle().Foreground(t.Warning).Render(fmt.Sprintf("Overwrites %d non-empty cell(s)", overwrite)) + "\n"
+	}
+	if computed > 0 {
+		content += lipgloss.NewStyle().Foreground(t.Warning).Render(fmt.Sprintf("Skips %d computed cell(s)", computed)) + "\n"
+	}
+	content += "\n"
+
+	// Preview grid of the first few rows and columns
+	const previewRows, previewCols, previewWidth = 6, 5, 10
+	header := lipgloss.NewStyle().Foreground(t.Secondary).Bold(true)
+	cell := lipgloss.NewStyle().Foreground(t.Text)
+	content += strings.Repeat(" ", 6)
+	for c := 0; c < ui.Min(cols, previewCols); c++ {
+		content += header.Render(ui.PadCenter(ui.ColIndexToLetter(m.cursorCol+c), previewWidth)) + " "
+	}
+	content += "\n"
+	for r := 0; r < ui.Min(rows, previewRows); r++ {
+		content += header.Render(fmt.Sprintf("%5d ", m.cursorRow+r+1))
+		for c := 0; c < ui.Min(cols, previewCols); c++ {
+			text := ""
+			if c < len(block[r]) {
+				text = block[r][c]
+			}
+			content += cell.Render(ui.TruncateToWidth(text, previewWidth)) + " "
+		}
+		content += "\n"
+	}
+	if rows > previewRows || cols > previewCols {
+		content += lipgloss.NewStyle().Foreground(t.DimText).Render("  …") + "\n"
+	}
+	content += "\n"
+
+	toggle := func(on bool) string {
+		if on {
+			return lipgloss.NewStyle().Foreground(t.Success).Render("[x]")
+		}
+		return lipgloss.NewStyle().Foreground(t.DimText).Render("[ ]")
+	}
+	content += toggle(m.pasteValuesOnly) + m.styles.ModalValue.Render(" v  Values only (formulas pasted as text)") + "\n"
+	content += toggle(m.pasteTranspose) + m.styles.ModalValue.Render(" t  Transpose") + "\n\n"
+	content += lipgloss.NewStyle().
+		Foreground(t.DimText).
+		Italic(true).
+		Render("Enter to paste, Esc to cancel")
+
+	return m.styles.Modal.Width(70).Render(content)
+}
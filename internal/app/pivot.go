@@ -0,0 +1,194 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vex/internal/pivot"
+	"github.com/vex/internal/theme"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+// pivotListHeight is the number of fields shown at once in the pivot builder
+const pivotListHeight = 12
+
+// startPivot opens the pivot builder for the current sheet, keeping the
+// previous layout when it was built from the same sheet
+func (m Model) startPivot() (tea.Model, tea.Cmd) {
+	if m.pivotSheet != m.currentSheet || m.pivotValue >= m.sheets[m.currentSheet].MaxCols {
+		m.pivotSheet = m.currentSheet
+		m.pivotRows = nil
+		m.pivotCols = nil
+		m.pivotValue = m.cursorCol
+		m.pivotAgg = pivot.Sum
+	}
+	m.pivotCursor = m.cursorCol
+	m.mode = models.ModePivot
+	return m, nil
+}
+
+// updatePivot handles the pivot builder
+func (m Model) updatePivot(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	maxCol := ui.Max(0, m.sheets[m.currentSheet].MaxCols-1)
+
+	switch msg.String() {
+	case "esc", "q":
+		m.mode = models.ModeNormal
+	case "up", "k":
+		if m.pivotCursor > 0 {
+			m.pivotCursor--
+		}
+	case "down", "j":
+		if m.pivotCursor < maxCol {
+			m.pivotCursor++
+		}
+	case "r":
+		m.pivotRows = toggleField(m.pivotRows, m.pivotCursor)
+		m.pivotCols = removeField(m.pivotCols, m.pivotCursor)
+	case "c":
+		m.pivotCols = toggleField(m.pivotCols, m.pivotCursor)
+		m.pivotRows = removeField(m.pivotRows, m.pivotCursor)
+	case "v", " ":
+		m.pivotValue = m.pivotCursor
+	case "a", "tab":
+		m.pivotAgg = pivot.Aggregates[(int(m.pivotAgg)+1)%len(pivot.Aggregates)]
+	case "A", "shift+tab":
+		m.pivotAgg = pivot.Aggregates[(int(m.pivotAgg)+len(pivot.Aggregates)-1)%len(pivot.Aggregates)]
+	case "x":
+		m.pivotRows = nil
+		m.pivotCols = nil
+	case "enter":
+		m.buildPivot()
+	}
+	return m, nil
+}
+
+// buildPivot computes the pivot table of the rows the autofilter shows and
+// opens it as a new sheet
+func (m *Model) buildPivot() {
+	sheet := m.materialize(m.currentSheet)
+	spec := pivot.Spec{
+		Rows:      m.pivotRows,
+		Cols:      m.pivotCols,
+		Value:     m.pivotValue,
+		Agg:       m.pivotAgg,
+		HeaderRow: m.headerRow(),
+	}
+	values, err := pivot.Build(&sheet, spec, m.rowHidden)
+	if err != nil {
+		m.status = models.StatusMsg{Message: "Pivot: " + err.Error(), Type: models.StatusError}
+		return
+	}
+
+	source := sheet.Name
+	label := fmt.Sprintf("%s of %s", spec.Agg, pivot.FieldName(&sheet, spec.HeaderRow, spec.Value))
+	m.mode = models.ModeNormal
+	m.addVirtualSheet("Pivot", values)
+	m.status = models.StatusMsg{
+		Message: fmt.Sprintf("Pivot of %s: %s by %s", source, label, m.pivotFieldNames(m.pivotRows)),
+		Type:    models.StatusSuccess,
+	}
+}
+
+// pivotFieldNames joins the names of fields of the pivot source sheet
+func (m *Model) pivotFieldNames(fields []int) string {
+	sheet := &m.sheets[m.pivotSheet]
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = pivot.FieldName(sheet, m.headerRowOf(m.pivotSheet), f)
+	}
+	return strings.Join(names, " › ")
+}
+
+// toggleField adds a field to the end of a list or removes it
+func toggleField(fields []int, col int) []int {
+	for _, f := range fields {
+		if f == col {
+			return removeField(fields, col)
+		}
+	}
+	return append(fields, col)
+}
+
+// removeField returns fields without col
+func removeField(fields []int, col int) []int {
+	out := make([]int, 0, len(fields))
+	for _, f := range fields {
+		if f != col {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// fieldIndex returns the 1-based position of col in fields, or 0
+func fieldIndex(fields []int, col int) int {
+	for i, f := range fields {
+		if f == col {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// renderPivot renders the pivot builder
+func (m Model) renderPivot() string {
+	t := theme.GetCurrentTheme()
+	dim := lipgloss.NewStyle().Foreground(t.DimText)
+	text := lipgloss.NewStyle().Foreground(t.Text)
+	selected := lipgloss.NewStyle().Foreground(t.Accent).Bold(true)
+	tag := lipgloss.NewStyle().Foreground(t.Success).Bold(true)
+
+	sheet := &m.sheets[m.currentSheet]
+	header := m.headerRow()
+	content := m.styles.ModalTitle.Render("⊞ Pivot Table") + "\n\n"
+
+	none := dim.Render("none")
+	rows, cols := none, none
+	if len(m.pivotRows) > 0 {
+		rows = m.styles.ModalValue.Render(m.pivotFieldNames(m.pivotRows))
+	}
+	if len(m.pivotCols) > 0 {
+		cols = m.styles.ModalValue.Render(m.pivotFieldNames(m.pivotCols))
+	}
+	content += m.styles.ModalKey.Render("Rows:    ") + rows + "\n"
+	content += m.styles.ModalKey.Render("Columns: ") + cols + "\n"
+	content += m.styles.ModalKey.Render("Values:  ") + m.styles.ModalValue.Render(
+		fmt.Sprintf("%s of %s", m.pivotAgg, pivot.FieldName(sheet, header, m.pivotValue))) + "\n\n"
+
+	start := ui.Max(0, ui.Min(m.pivotCursor-pivotListHeight/2, sheet.MaxCols-pivotListHeight))
+	end := ui.Min(start+pivotListHeight, sheet.MaxCols)
+	if start > 0 {
+		content += dim.Render(fmt.Sprintf("    ↑ %d more", start)) + "\n"
+	}
+	for col := start; col < end; col++ {
+		role := "    "
+		if i := fieldIndex(m.pivotRows, col); i > 0 {
+			role = fmt.Sprintf("R%-3d", i)
+		} else if i := fieldIndex(m.pivotCols, col); i > 0 {
+			role = fmt.Sprintf("C%-3d", i)
+		}
+		value := "  "
+		if col == m.pivotValue {
+			value = "Σ "
+		}
+		name := fmt.Sprintf("%-3s %s", ui.ColIndexToLetter(col), ui.Truncate(pivot.FieldName(sheet, header, col), 40))
+		if col == m.pivotCursor {
+			content += selected.Render("→ ") + tag.Render(role+value) + selected.Render(name) + "\n"
+		} else {
+			content += "  " + tag.Render(role+value) + text.Render(name) + "\n"
+		}
+	}
+	if end < sheet.MaxCols {
+		content += dim.Render(fmt.Sprintf("    ↓ %d more", sheet.MaxCols-end)) + "\n"
+	}
+
+	content += "\n" + dim.Italic(true).Render(strings.Join([]string{
+		"r row field", "c column field", "v value field", "a aggregate", "x reset", "Enter build", "Esc cancel",
+	}, " • "))
+
+	return m.styles.Modal.Width(72).Render(content)
+}
@@ -0,0 +1,170 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vex/internal/profile"
+	"github.com/vex/internal/theme"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+// profileListHeight is the number of columns shown at once in the profile
+const profileListHeight = 10
+
+// Profiles returns the profile of every sheet of a file as the profile
+// panel shows it, with the session of the file restored, computed columns
+// evaluated and the rows the autofilter hides left out
+func Profiles(filename string, sheets []models.Sheet) []profile.Report {
+	m := NewModel(filename, sheets, "")
+	reports := make([]profile.Report, len(m.sheets))
+	for i := range m.sheets {
+		reports[i] = m.profileSheet(i)
+	}
+	return reports
+}
+
+// profileSheet profiles a sheet below its header row, leaving out the rows
+// its autofilter hides
+func (m *Model) profileSheet(sheetIdx int) profile.Report {
+	sheet := m.materialize(sheetIdx)
+	f := sheet.Filter
+	return profile.Profile(&sheet, m.headerRowOf(sheetIdx), func(row int) bool { return f != nil && f.IsHidden(row) })
+}
+
+// startProfile profiles the current sheet and opens the report on the
+// cursor column
+func (m Model) startProfile() (tea.Model, tea.Cmd) {
+	report := m.profileSheet(m.currentSheet)
+	m.profileReport = &report
+	m.profileCursor = ui.Min(m.cursorCol, ui.Max(0, len(report.Columns)-1))
+	m.mode = models.ModeProfile
+	return m, nil
+}
+
+// updateProfile handles the profile report
+func (m Model) updateProfile(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	columns := m.profileReport.Columns
+	switch msg.String() {
+	case "esc", "q":
+		m.mode = models.ModeNormal
+	case "up", "k":
+		if m.profileCursor > 0 {
+			m.profileCursor--
+		}
+	case "down", "j":
+		if m.profileCursor < len(columns)-1 {
+			m.profileCursor++
+		}
+	case "tab":
+		m.profileCursor = nextFlagged(columns, m.profileCursor, 1)
+	case "shift+tab":
+		m.profileCursor = nextFlagged(columns, m.profileCursor, -1)
+	case "enter":
+		if m.profileCursor < len(columns) {
+			c := columns[m.profileCursor]
+			row := -1
+			if len(c.Issues) > 0 {
+				row = c.Issues[0].Row
+			}
+			m.mode = models.ModeNormal
+			m.pushJumpHistory()
+			m.moveTo(position{sheet: m.currentSheet, row: row, col: c.Col})
+		}
+	}
+	return m, nil
+}
+
+// nextFlagged returns the next column with issues in direction dir, or
+// from when there is none
+func nextFlagged(columns []profile.Column, from, dir int) int {
+	for i := 1; i < len(columns); i++ {
+		idx := ((from+dir*i)%len(columns) + len(columns)) % len(columns)
+		if len(columns[idx].Issues) > 0 {
+			return idx
+		}
+	}
+	return from
+}
+
+// renderProfile renders the profile report
+func (m Model) renderProfile() string {
+	t := theme.GetCurrentTheme()
+	dim := lipgloss.NewStyle().Foreground(t.DimText)
+	text := lipgloss.NewStyle().Foreground(t.Text)
+	selected := lipgloss.NewStyle().Foreground(t.Accent).Bold(true)
+	warning := lipgloss.NewStyle().Foreground(t.Warning)
+	accent := lipgloss.NewStyle().Foreground(t.Accent)
+
+	r := m.profileReport
+	content := m.styles.ModalTitle.Render("🔬 Profile of "+ui.Truncate(r.Sheet, 40)) + "\n\n"
+	content += text.Render(fmt.Sprintf("%d rows • %d columns • ", r.Rows, len(r.Columns)))
+	if n := r.Flagged(); n > 0 {
+		content += warning.Render(fmt.Sprintf("%d flagged", n))
+	} else {
+		content += text.Render("nothing flagged")
+	}
+	content += "\n"
+	if m.sheets[m.currentSheet].Filter != nil {
+		content += dim.Render("Rows hidden by the filter are left out") + "\n"
+	}
+	content += "\n" + dim.Render(fmt.Sprintf("  %-4s%-16s %-8s %6s %8s  %-16s", "", "Name", "Type", "Nulls", "Distinct", "Pattern")) + "\n"
+
+	start := ui.Max(0, ui.Min(m.profileCursor-profileListHeight/2, len(r.Columns)-profileListHeight))
+	end := ui.Min(start+profileListHeight, len(r.Columns))
+	if start > 0 {
+		content += dim.Render(fmt.Sprintf("    ↑ %d more", start)) + "\n"
+	}
+	for i := start; i < end; i++ {
+		c := r.Columns[i]
+		pattern := ""
+		if len(c.Patterns) > 0 {
+			pattern = c.Patterns[0].Pattern
+		}
+		line := fmt.Sprintf("%-4s%-16s %-8s %6s %8d  %-16s", c.Letter, ui.Truncate(c.Name, 16), c.Type,
+			profile.FormatRate(c.NullRate), c.Distinct, ui.Truncate(pattern, 16))
+		mark := "  "
+		if len(c.Issues) > 0 {
+			mark = warning.Render(" ⚠")
+		}
+		if i == m.profileCursor {
+			content += selected.Render("→ "+line) + mark + "\n"
+		} else {
+			content += "  " + text.Render(line) + mark + "\n"
+		}
+	}
+	if end < len(r.Columns) {
+		content += dim.Render(fmt.Sprintf("    ↓ %d more", len(r.Columns)-end)) + "\n"
+	}
+
+	if m.profileCursor < len(r.Columns) {
+		c := &r.Columns[m.profileCursor]
+		field := func(label, value string) string {
+			return m.styles.ModalKey.Render(fmt.Sprintf("%-10s", label)) + m.styles.ModalValue.Render(value) + "\n"
+		}
+		content += "\n" + accent.Bold(true).Render("Column "+c.Letter) + "\n"
+		content += field("Patterns", ui.Truncate(c.PatternSummary(), 56))
+		content += field("Length", c.LengthRange())
+		content += field("Range", ui.Truncate(c.ValueRange(), 56))
+		if len(c.Types) > 0 {
+			var types []string
+			for _, t := range []profile.Type{profile.TypeNumber, profile.TypeDate, profile.TypeBool, profile.TypeText} {
+				if n := c.Types[t]; n > 0 {
+					types = append(types, fmt.Sprintf("%s %d", t, n))
+				}
+			}
+			content += field("Types", strings.Join(types, ", "))
+		}
+		for _, is := range c.Issues {
+			content += warning.Render(ui.Truncate(fmt.Sprintf("⚠ %s: %d value(s), first at row %d: %q",
+				is.Flag, is.Count, is.Row+1, is.Example), 66)) + "\n"
+		}
+	}
+
+	help := []string{"Enter go to column", "Tab next flagged", "Esc close"}
+	content += "\n" + dim.Italic(true).Render(strings.Join(help, " • "))
+	return m.styles.Modal.Width(72).Render(content)
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/vex/pkg/models"
+)
+
+// promptHistory tracks browsing through the saved entries of a prompt
+type promptHistory struct {
+	pos    int    // Index of the shown entry, or -1 when not browsing
+	prefix string // Text typed before browsing, entries must start with it
+}
+
+// resetPromptHistory stops browsing, e.g. when a prompt opens or is edited
+func (m *Model) resetPromptHistory() {
+	m.promptHist = promptHistory{pos: -1}
+}
+
+// browsePromptHistory replaces the input with the previous (dir -1) or
+// next (dir 1) saved entry starting with the text typed before browsing.
+// Moving past the newest entry restores the typed text.
+func (m *Model) browsePromptHistory(input *textinput.Model, name string, dir int) {
+	entries := m.inputHistory[name]
+	h := &m.promptHist
+	if h.pos < 0 {
+		if dir > 0 {
+			return
+		}
+		h.prefix = input.Value()
+		h.pos = len(entries)
+	}
+
+	for i := h.pos + dir; i >= 0 && i < len(entries); i += dir {
+		if strings.HasPrefix(entries[i], h.prefix) {
+			h.pos = i
+			input.SetValue(entries[i])
+			input.CursorEnd()
+			return
+		}
+	}
+	if dir > 0 {
+		input.SetValue(h.prefix)
+		input.CursorEnd()
+		m.resetPromptHistory()
+	}
+}
+
+// recordPrompt saves an entered prompt value to the persistent history
+func (m *Model) recordPrompt(name, value string) {
+	m.resetPromptHistory()
+	if strings.TrimSpace(value) == "" || m.inputHistory == nil {
+		return
+	}
+	m.inputHistory.Add(name, value)
+	if err := m.inputHistory.Save(); err != nil {
+		m.status = models.StatusMsg{Message: err.Error(), Type: models.StatusWarning}
+	}
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vex/internal/sql"
+	"github.com/vex/internal/theme"
+	"github.com/vex/pkg/models"
+)
+
+// startQuery opens the SQL prompt
+func (m Model) startQuery() (tea.Model, tea.Cmd) {
+	m.mode = models.ModeQuery
+	m.resetPromptHistory()
+	m.queryInput.Width = max(20, m.width-48)
+	m.queryInput.Focus()
+	m.queryInput.CursorEnd()
+	return m, textinput.Blink
+}
+
+// updateQuery handles the SQL prompt
+func (m Model) updateQuery(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.Type {
+	case tea.KeyEscape:
+		m.mode = models.ModeNormal
+		m.queryInput.Blur()
+		return m, nil
+
+	case tea.KeyUp:
+		m.browsePromptHistory(&m.queryInput, "sql", -1)
+		return m, nil
+
+	case tea.KeyDown:
+		m.browsePromptHistory(&m.queryInput, "sql", 1)
+		return m, nil
+
+	case tea.KeyEnter:
+		query := strings.TrimSpace(m.queryInput.Value())
+		if query == "" {
+			m.mode = models.ModeNormal
+			m.queryInput.Blur()
+			return m, nil
+		}
+		m.recordPrompt("sql", query)
+		m.runQuery(query)
+		return m, nil
+	}
+
+	before := m.queryInput.Value()
+	m.queryInput, cmd = m.queryInput.Update(msg)
+	if m.queryInput.Value() != before {
+		m.resetPromptHistory()
+	}
+	return m, cmd
+}
+
+// runQuery runs a query over every sheet and opens the result as a new
+// sheet. On error the prompt stays open with the cursor at the offending
+// position.
+func (m *Model) runQuery(query string) {
+	tables := make([]sql.Table, len(m.sheets))
+	for i := range m.sheets {
+		sheet := m.materialize(i)
+		tables[i] = sql.TableFromSheet(tableName(sheet.Name), &sheet, m.headerRowOf(i))
+	}
+
+	values, err := sql.Run(query, tables)
+	if err != nil {
+		var qerr *sql.Error
+		if errors.As(err, &qerr) {
+			// Positions are byte offsets; the input cursor counts runes
+			m.queryInput.SetCursor(len([]rune(m.queryInput.Value()[:min(qerr.Pos, len(m.queryInput.Value()))])))
+		}
+		m.status = models.StatusMsg{Message: "SQL: " + err.Error(), Type: models.StatusError}
+		return
+	}
+
+	m.mode = models.ModeNormal
+	m.queryInput.Blur()
+	m.addVirtualSheet("Query", values)
+	m.status = models.StatusMsg{
+		Message: fmt.Sprintf("Query returned %d rows", len(values)-1),
+		Type:    models.StatusSuccess,
+	}
+}
+
+// tableName is the name a sheet goes by in queries: a CSV sheet, named
+// after its file, drops the extension
+func tableName(sheet string) string {
+	switch strings.ToLower(filepath.Ext(sheet)) {
+	case ".csv", ".tsv", ".txt":
+		return strings.TrimSuffix(sheet, filepath.Ext(sheet))
+	}
+	return sheet
+}
+
+// renderQueryBar renders the SQL prompt at the bottom of the screen
+func (m Model) renderQueryBar() string {
+	t := theme.GetCurrentTheme()
+	prompt := m.styles.SearchPrompt.Render(":")
+	hint := lipgloss.NewStyle().
+		Foreground(t.DimText).
+		Render("  (Enter run, ↑/↓ history, Esc cancel)")
+	return m.styles.SearchBar.Render(prompt + m.queryInput.View() + hint)
+}
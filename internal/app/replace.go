@@ -0,0 +1,353 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vex/internal/loader"
+	"github.com/vex/internal/theme"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+// searchScope is the part of the workbook a search or replace covers
+type searchScope int
+
+const (
+	scopeSheet searchScope = iota
+	scopeSelection
+	scopeWorkbook
+)
+
+// String returns the display name of the scope
+func (s searchScope) String() string {
+	switch s {
+	case scopeSelection:
+		return "Selection"
+	case scopeWorkbook:
+		return "All sheets"
+	default:
+		return "Sheet"
+	}
+}
+
+// replaceState is the review state of a single match
+type replaceState int
+
+const (
+	replacePending replaceState = iota
+	replaceDone
+	replaceSkipped
+)
+
+// replaceMatch is a cell matched by find and replace
+type replaceMatch struct {
+	sheet  int
+	before models.Cell
+	after  models.Cell
+	state  replaceState
+}
+
+// startReplace opens the find and replace dialog
+func (m Model) startReplace() (tea.Model, tea.Cmd) {
+	m.mode = models.ModeReplace
+	m.replaceReview = false
+	m.replaceMatches = nil
+	m.replaceScope = scopeSheet
+	if m.isSelecting {
+		m.replaceScope = scopeSelection
+	}
+	if m.replaceFind.Value() == "" && m.searchQuery != "" {
+		m.replaceFind.SetValue(m.searchQuery)
+	}
+	m.replaceFind.CursorEnd()
+	m.replaceFind.Focus()
+	m.replaceWith.Blur()
+	return m, textinput.Blink
+}
+
+// updateReplace handles the find and replace dialog
+func (m Model) updateReplace(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.replaceReview {
+		return m.updateReplaceReview(msg)
+	}
+
+	var cmd tea.Cmd
+	switch msg.String() {
+	case "esc":
+		m.mode = models.ModeNormal
+		m.replaceFind.Blur()
+		m.replaceWith.Blur()
+		return m, nil
+	case "tab", "shift+tab", "up", "down":
+		if m.replaceFind.Focused() {
+			m.replaceFind.Blur()
+			m.replaceWith.Focus()
+		} else {
+			m.replaceWith.Blur()
+			m.replaceFind.Focus()
+		}
+		return m, textinput.Blink
+	case "alt+s":
+		m.replaceScope = (m.replaceScope + 1) % 3
+		if m.replaceScope == scopeSelection && !m.isSelecting {
+			m.replaceScope = scopeWorkbook
+		}
+		return m, nil
+	case "enter":
+		m.findReplaceMatches()
+		return m, nil
+	}
+
+	if toggleSearchOption(&m.replaceOpts, msg.String()) {
+		return m, nil
+	}
+
+	if m.replaceFind.Focused() {
+		m.replaceFind, cmd = m.replaceFind.Update(msg)
+	} else {
+		m.replaceWith, cmd = m.replaceWith.Update(msg)
+	}
+	return m, cmd
+}
+
+// findReplaceMatches collects every cell in scope that the replacement
+// would change and switches the dialog to the review list. Cells of
+// computed columns follow their expression and are left out.
+func (m *Model) findReplaceMatches() {
+	term := m.replaceFind.Value()
+	if term == "" {
+		m.status = models.StatusMsg{Message: "Enter text to find", Type: models.StatusWarning}
+		return
+	}
+	matcher, err := loader.NewMatcher(term, m.replaceOpts)
+	if err != nil {
+		m.status = models.StatusMsg{Message: err.Error(), Type: models.StatusError}
+		return
+	}
+	replacement := m.replaceWith.Value()
+
+	m.replaceMatches = nil
+	for s := range m.sheets {
+		if m.replaceScope != scopeWorkbook && s != m.currentSheet {
+			continue
+		}
+		startRow, startCol, endRow, endCol := 0, 0, m.sheets[s].MaxRows-1, m.sheets[s].MaxCols-1
+		if m.replaceScope == scopeSelection {
+			startRow, startCol, endRow, endCol = m.copyBounds()
+		}
+
+		for row := startRow; row <= endRow && row < len(m.sheets[s].Rows); row++ {
+			for col := startCol; col <= endCol && col < len(m.sheets[s].Rows[row]); col++ {
+				if m.sheets[s].ComputedCell(row, col) != nil {
+					continue
+				}
+				cell := m.sheets[s].Rows[row][col]
+				after := cell
+				switch {
+				case cell.Formula != "":
+					if !m.replaceOpts.Formulas || !matcher.Match(cell.Formula) {
+						continue
+					}
+					// As in commitEdit, a rewritten formula has no value yet
+					after.Formula = matcher.Replace(cell.Formula, replacement)
+					if after.Formula != cell.Formula {
+						after.Value = ""
+					}
+				case matcher.Match(cell.Value):
+					after.Value = matcher.Replace(cell.Value, replacement)
+				default:
+					continue
+				}
+				if after.Value == cell.Value && after.Formula == cell.Formula {
+					continue
+				}
+				m.replaceMatches = append(m.replaceMatches, replaceMatch{sheet: s, before: cell, after: after})
+			}
+		}
+	}
+
+	if len(m.replaceMatches) == 0 {
+		m.status = models.StatusMsg{Message: "No matches to replace", Type: models.StatusWarning}
+		return
+	}
+	m.replaceIndex = 0
+	m.replaceReview = true
+	m.replaceFind.Blur()
+	m.replaceWith.Blur()
+	m.status = models.StatusMsg{
+		Message: fmt.Sprintf("%d match(es) in %s", len(m.replaceMatches), strings.ToLower(m.replaceScope.String())),
+		Type:    models.StatusInfo,
+	}
+}
+
+// updateReplaceReview handles the match list of find and replace
+func (m Model) updateReplaceReview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.finishReplace()
+	case "up", "k":
+		if m.replaceIndex > 0 {
+			m.replaceIndex--
+		}
+	case "down", "j":
+		if m.replaceIndex < len(m.replaceMatches)-1 {
+			m.replaceIndex++
+		}
+	case "y", "enter":
+		m.replaceMatches[m.replaceIndex].state = replaceDone
+		m.nextPendingMatch()
+	case "n":
+		m.replaceMatches[m.replaceIndex].state = replaceSkipped
+		m.nextPendingMatch()
+	case "a":
+		for i := range m.replaceMatches {
+			if m.replaceMatches[i].state == replacePending {
+				m.replaceMatches[i].state = replaceDone
+			}
+		}
+		m.finishReplace()
+	}
+	return m, nil
+}
+
+// nextPendingMatch moves to the next undecided match, finishing the
+// review when every match has been replaced or skipped
+func (m *Model) nextPendingMatch() {
+	for i := 1; i <= len(m.replaceMatches); i++ {
+		next := (m.replaceIndex + i) % len(m.replaceMatches)
+		if m.replaceMatches[next].state == replacePending {
+			m.replaceIndex = next
+			return
+		}
+	}
+	m.finishReplace()
+}
+
+// finishReplace writes the accepted replacements as one undoable action
+func (m *Model) finishReplace() {
+	m.mode = models.ModeNormal
+	m.replaceReview = false
+
+	var edits []*cellEdit
+	bySheet := make(map[int]*cellEdit)
+	total := 0
+	for _, match := range m.replaceMatches {
+		if match.state != replaceDone {
+			continue
+		}
+		edit, ok := bySheet[match.sheet]
+		if !ok {
+			edit = &cellEdit{sheet: match.sheet}
+			bySheet[match.sheet] = edit
+			edits = append(edits, edit)
+		}
+		edit.changes = append(edit.changes, cellChange{
+			row:    match.before.Row,
+			col:    match.before.Col,
+			before: match.before,
+			after:  match.after,
+		})
+		total++
+	}
+	found := len(m.replaceMatches)
+	m.replaceMatches = nil
+
+	if total == 0 {
+		m.status = models.StatusMsg{Message: "Nothing replaced", Type: models.StatusInfo}
+		return
+	}
+	m.execute(&batchEdit{
+		name:  fmt.Sprintf("Replace %d of %d match(es) of %q", total, found, m.replaceFind.Value()),
+		edits: edits,
+	})
+}
+
+// renderReplace renders the find and replace dialog
+func (m Model) renderReplace() string {
+	t := theme.GetCurrentTheme()
+	dim := lipgloss.NewStyle().Foreground(t.DimText)
+
+	content := m.styles.ModalTitle.Render("🔁 Find and Replace") + "\n\n"
+	if m.replaceReview {
+		content += m.renderReplaceReview()
+		return m.styles.Modal.Width(78).Render(content)
+	}
+
+	content += m.styles.ModalKey.Render("Find:") + "\n" + m.replaceFind.View() + "\n\n"
+	content += m.styles.ModalKey.Render("Replace with:") + "\n" + m.replaceWith.View() + "\n\n"
+
+	toggle := func(on bool) string {
+		if on {
+			return lipgloss.NewStyle().Foreground(t.Success).Render("[x]")
+		}
+		return dim.Render("[ ]")
+	}
+	content += toggle(m.replaceOpts.Regex) + m.styles.ModalValue.Render(" alt+r  Regex ($1 inserts a capture group)") + "\n"
+	content += toggle(m.replaceOpts.CaseSensitive) + m.styles.ModalValue.Render(" alt+c  Match case") + "\n"
+	content += toggle(m.replaceOpts.WholeWord) + m.styles.ModalValue.Render(" alt+w  Whole word") + "\n"
+	content += toggle(m.replaceOpts.WholeCell) + m.styles.ModalValue.Render(" alt+e  Entire cell") + "\n"
+	content += toggle(m.replaceOpts.Formulas) + m.styles.ModalValue.Render(" alt+f  Include formulas") + "\n"
+	content += m.styles.ModalKey.Render("    alt+s  Scope: ") + m.styles.ModalValue.Render(m.replaceScope.String()) + "\n\n"
+	content += dim.Italic(true).Render("Tab switch field • Enter preview matches • Esc cancel")
+
+	return m.styles.Modal.Width(78).Render(content)
+}
+
+// renderReplaceReview renders the list of matches with their replacements
+func (m Model) renderReplaceReview() string {
+	t := theme.GetCurrentTheme()
+	dim := lipgloss.NewStyle().Foreground(t.DimText)
+	text := lipgloss.NewStyle().Foreground(t.Text)
+	selected := lipgloss.NewStyle().Foreground(t.Accent).Bold(true)
+
+	shown := func(cell models.Cell) string {
+		if cell.Formula != "" {
+			return "=" + cell.Formula
+		}
+		return cell.Value
+	}
+
+	var content string
+	done := 0
+	for _, match := range m.replaceMatches {
+		if match.state == replaceDone {
+			done++
+		}
+	}
+	content += m.styles.ModalKey.Render("Matches: ") +
+		m.styles.ModalValue.Render(fmt.Sprintf("%d/%d • %d to replace", m.replaceIndex+1, len(m.replaceMatches), done)) + "\n\n"
+
+	const maxShown = 12
+	start := ui.Max(0, ui.Min(m.replaceIndex-maxShown/2, len(m.replaceMatches)-maxShown))
+	for i := start; i < len(m.replaceMatches) && i < start+maxShown; i++ {
+		match := m.replaceMatches[i]
+		marker := "  "
+		switch match.state {
+		case replaceDone:
+			marker = "✓ "
+		case replaceSkipped:
+			marker = "· "
+		}
+		line := fmt.Sprintf("%-12s %s → %s",
+			ui.Truncate(m.sheetCellLabel(match.sheet, match.before.Row, match.before.Col), 12),
+			ui.TruncateToWidth(shown(match.before), 24),
+			ui.TruncateToWidth(shown(match.after), 24))
+		switch {
+		case i == m.replaceIndex:
+			content += selected.Render("→ "+line) + "\n"
+		case match.state == replaceSkipped:
+			content += dim.Render(marker+line) + "\n"
+		default:
+			content += text.Render(marker+line) + "\n"
+		}
+	}
+
+	content += "\n" + dim.Italic(true).Render(strings.Join([]string{
+		"y replace", "n skip", "a replace all", "↑/↓ select", "Esc finish",
+	}, " • "))
+	return content
+}
@@ -0,0 +1,399 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vex/internal/loader"
+	"github.com/vex/internal/theme"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+// searchDebounce is how long live search waits after a keystroke
+const searchDebounce = 150 * time.Millisecond
+
+// searchResult is a matching cell and the sheet it is on
+type searchResult struct {
+	sheet int
+	cell  models.Cell
+}
+
+// searchOutcome holds the results of one search run
+type searchOutcome struct {
+	results []searchResult
+	rows    bool            // Results are whole rows matched by a column query
+	matcher *loader.Matcher // Text matcher, nil for column queries
+}
+
+// searchTickMsg fires when the live search debounce delay has passed
+type searchTickMsg struct {
+	seq     int
+	refresh bool // Repeat the active search rather than the typed one
+}
+
+// searchDoneMsg delivers the results of a background live search
+type searchDoneMsg struct {
+	seq     int
+	refresh bool
+	outcome searchOutcome
+	err     error
+}
+
+// findResults searches the current sheet, or every sheet when all is set.
+// Terms naming a header column, such as Price>100, match whole rows below
+// the header row of each sheet, given by headers; sheets without the
+// queried columns are skipped. It returns the context's error when
+// cancelled.
+func findResults(ctx context.Context, sheets []models.Sheet, headers []int, current int, all bool, opts loader.SearchOptions, term string) (searchOutcome, error) {
+	searched := func(s int) bool { return all || s == current }
+
+	if query, err := loader.ParseQuery(term); err == nil {
+		isColumnQuery := false
+		for s, sheet := range sheets {
+			if searched(s) && loader.HeaderIndex(loader.HeaderRow(sheet, headers[s]), query.FirstColumn()) >= 0 {
+				isColumnQuery = true
+			}
+		}
+		if isColumnQuery {
+			outcome := searchOutcome{rows: true}
+			var bindErr error
+			for s, sheet := range sheets {
+				if !searched(s) {
+					continue
+				}
+				filter, err := query.Bind(loader.HeaderRow(sheet, headers[s]))
+				if err != nil {
+					bindErr = err
+					continue
+				}
+				rows, err := loader.FilterRows(ctx, sheet, headers[s], filter)
+				if err != nil {
+					return searchOutcome{}, err
+				}
+				for _, row := range rows {
+					outcome.results = append(outcome.results, searchResult{sheet: s, cell: sheet.CellAt(row, filter.Column)})
+				}
+			}
+			if len(outcome.results) == 0 && bindErr != nil {
+				return searchOutcome{}, bindErr
+			}
+			return outcome, nil
+		}
+	}
+
+	text, opts := loader.ParseModifiers(term, opts)
+	matcher, err := loader.NewMatcher(text, opts)
+	if err != nil {
+		return searchOutcome{}, err
+	}
+
+	outcome := searchOutcome{matcher: matcher}
+	for s, sheet := range sheets {
+		if !searched(s) {
+			continue
+		}
+		cells, err := loader.SearchSheet(ctx, sheet, matcher)
+		if err != nil {
+			return searchOutcome{}, err
+		}
+		for _, cell := range cells {
+			outcome.results = append(outcome.results, searchResult{sheet: s, cell: cell})
+		}
+	}
+	return outcome, nil
+}
+
+// runSearch finds the term and selects the first result at or after the cursor
+func (m *Model) runSearch(term string) error {
+	m.cancelSearch()
+	outcome, err := findResults(context.Background(), m.materializeAll(), m.headerRows(), m.currentSheet, m.searchAll, m.searchOpts, term)
+	if err != nil {
+		return err
+	}
+	m.searchQuery = term
+	m.applySearch(outcome)
+	return nil
+}
+
+// applySearch installs search results and indexes them by position so
+// the table can look up matches without scanning the result list
+func (m *Model) applySearch(outcome searchOutcome) {
+	m.searchResults = outcome.results
+	m.searchRows = outcome.rows
+	m.searchMatcher = outcome.matcher
+	m.searchHits = make(map[position]bool, len(outcome.results))
+	for _, result := range outcome.results {
+		col := result.cell.Col
+		if outcome.rows {
+			col = -1
+		}
+		m.searchHits[position{sheet: result.sheet, row: result.cell.Row, col: col}] = true
+	}
+	m.searchIndex = 0
+	m.outlierNav = false
+	m.selectNearestResult()
+}
+
+// refreshSearch repeats the active search in the background once the
+// searched sheets have changed, so highlights and n/N follow the cells'
+// new positions
+func (m *Model) refreshSearch() tea.Cmd {
+	m.searchStale = false
+	if m.searchQuery == "" {
+		return nil
+	}
+	return m.scheduleSearch(true)
+}
+
+// clearSearch removes the search results and highlights
+func (m *Model) clearSearch() {
+	m.cancelSearch()
+	m.searchQuery = ""
+	m.applySearch(searchOutcome{})
+}
+
+// cancelSearch stops a running live search
+func (m *Model) cancelSearch() {
+	if m.searchCancel != nil {
+		m.searchCancel()
+		m.searchCancel = nil
+	}
+}
+
+// scheduleLiveSearch restarts the debounce timer after the query changed
+func (m *Model) scheduleLiveSearch() tea.Cmd {
+	return m.scheduleSearch(false)
+}
+
+// scheduleSearch restarts the debounce timer of a live search or refresh
+func (m *Model) scheduleSearch(refresh bool) tea.Cmd {
+	m.cancelSearch()
+	m.searchSeq++
+	seq := m.searchSeq
+	return tea.Tick(searchDebounce, func(time.Time) tea.Msg {
+		return searchTickMsg{seq: seq, refresh: refresh}
+	})
+}
+
+// startLiveSearch runs the typed query in the background once the user
+// has paused typing, or repeats the active search after an edit
+func (m Model) startLiveSearch(msg searchTickMsg) (tea.Model, tea.Cmd) {
+	if msg.seq != m.searchSeq || !msg.refresh && m.mode != models.ModeSearch {
+		return m, nil
+	}
+	term := strings.TrimSpace(m.searchInput.Value())
+	if msg.refresh {
+		term = m.searchQuery
+		if term == "" {
+			return m, nil
+		}
+	}
+	if term == "" {
+		m.applySearch(searchOutcome{})
+		m.moveTo(m.searchOrigin)
+		return m, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.searchCancel = cancel
+	sheets, headers, current, all, opts, seq, refresh := m.materializeAll(), m.headerRows(), m.currentSheet, m.searchAll, m.searchOpts, m.searchSeq, msg.refresh
+	return m, func() tea.Msg {
+		outcome, err := findResults(ctx, sheets, headers, current, all, opts, term)
+		return searchDoneMsg{seq: seq, refresh: refresh, outcome: outcome, err: err}
+	}
+}
+
+// finishLiveSearch shows the results of a background search and moves
+// to the nearest match. Refreshed results replace the old ones in place.
+func (m Model) finishLiveSearch(msg searchDoneMsg) (tea.Model, tea.Cmd) {
+	if msg.seq != m.searchSeq || !msg.refresh && m.mode != models.ModeSearch || errors.Is(msg.err, context.Canceled) {
+		return m, nil
+	}
+	m.searchCancel = nil
+	if msg.refresh {
+		if msg.err != nil {
+			m.clearSearch()
+			return m, nil
+		}
+		nav := m.outlierNav
+		m.applySearch(msg.outcome)
+		m.outlierNav = nav
+		return m, nil
+	}
+	if msg.err != nil {
+		m.status = models.StatusMsg{Message: msg.err.Error(), Type: models.StatusError}
+		return m, nil
+	}
+
+	m.applySearch(msg.outcome)
+	if len(m.searchResults) == 0 {
+		m.moveTo(m.searchOrigin)
+		m.status = models.StatusMsg{Message: "No results found", Type: models.StatusWarning}
+		return m, nil
+	}
+	m.jumpToSearchResult()
+	return m, nil
+}
+
+// renderSearchCell renders a matching cell with the matched text emphasised
+func (m *Model) renderSearchCell(text string) string {
+	if m.searchMatcher == nil {
+		return m.styles.SearchMatch.Render(text)
+	}
+	loc := m.searchMatcher.Index(text)
+	if loc == nil || loc[0] == loc[1] {
+		return m.styles.SearchMatch.Render(text)
+	}
+
+	base := m.styles.SearchMatch.Copy().UnsetWidth()
+	emphasis := base.Copy().Background(theme.GetCurrentTheme().Accent).Underline(true)
+	return base.Render(text[:loc[0]]) + emphasis.Render(text[loc[0]:loc[1]]) + base.Render(text[loc[1]:])
+}
+
+// selectNearestResult selects the first result at or after the cursor
+func (m *Model) selectNearestResult() {
+	for i, result := range m.searchResults {
+		if result.sheet > m.currentSheet ||
+			result.sheet == m.currentSheet && (result.cell.Row > m.cursorRow ||
+				result.cell.Row == m.cursorRow && result.cell.Col >= m.cursorCol) {
+			m.searchIndex = i
+			break
+		}
+	}
+}
+
+// toggleSearchOption flips the search option bound to an alt key and
+// reports whether the key was one of them
+func toggleSearchOption(opts *loader.SearchOptions, key string) bool {
+	switch key {
+	case "alt+r":
+		opts.Regex = !opts.Regex
+	case "alt+c":
+		opts.CaseSensitive = !opts.CaseSensitive
+	case "alt+w":
+		opts.WholeWord = !opts.WholeWord
+		opts.WholeCell = false
+	case "alt+e":
+		opts.WholeCell = !opts.WholeCell
+		opts.WholeWord = false
+	case "alt+f":
+		opts.Formulas = !opts.Formulas
+	default:
+		return false
+	}
+	return true
+}
+
+// searchFlags summarises the active search options, e.g. ".* Aa =fx"
+func searchFlags(opts loader.SearchOptions, allSheets bool) string {
+	var flags []string
+	if opts.Regex {
+		flags = append(flags, ".*")
+	}
+	if opts.CaseSensitive {
+		flags = append(flags, "Aa")
+	}
+	if opts.WholeWord {
+		flags = append(flags, "\\b")
+	}
+	if opts.WholeCell {
+		flags = append(flags, "[cell]")
+	}
+	if opts.Formulas {
+		flags = append(flags, "=fx")
+	}
+	if allSheets {
+		flags = append(flags, "[all sheets]")
+	}
+	return strings.Join(flags, " ")
+}
+
+// updateSearchResults handles the search results list
+func (m Model) updateSearchResults(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "L":
+		m.mode = models.ModeNormal
+	case "up", "k":
+		if m.resultsCursor > 0 {
+			m.resultsCursor--
+		}
+	case "down", "j":
+		if m.resultsCursor < len(m.searchResults)-1 {
+			m.resultsCursor++
+		}
+	case "pgup", "ctrl+u":
+		m.resultsCursor = ui.Max(0, m.resultsCursor-10)
+	case "pgdown", "ctrl+d":
+		m.resultsCursor = ui.Min(len(m.searchResults)-1, m.resultsCursor+10)
+	case "enter":
+		m.pushJumpHistory()
+		m.searchIndex = m.resultsCursor
+		m.jumpToSearchResult()
+		m.mode = models.ModeNormal
+	}
+	return m, nil
+}
+
+// renderSearchResults renders the search results grouped by sheet, each
+// with its reference, value and the neighbouring cells of its row
+func (m Model) renderSearchResults() string {
+	t := theme.GetCurrentTheme()
+	dim := lipgloss.NewStyle().Foreground(t.DimText)
+	text := lipgloss.NewStyle().Foreground(t.Text)
+	selected := lipgloss.NewStyle().Foreground(t.Accent).Bold(true)
+	header := lipgloss.NewStyle().Foreground(t.Secondary).Bold(true)
+
+	content := m.styles.ModalTitle.Render("🔍 Results for "+m.searchQuery) + "\n\n"
+
+	const maxShown = 16
+	start := ui.Max(0, ui.Min(m.resultsCursor-maxShown/2, len(m.searchResults)-maxShown))
+	end := ui.Min(len(m.searchResults), start+maxShown)
+	for i := start; i < end; i++ {
+		result := m.searchResults[i]
+		if i == start || result.sheet != m.searchResults[i-1].sheet {
+			count := 0
+			for _, r := range m.searchResults {
+				if r.sheet == result.sheet {
+					count++
+				}
+			}
+			content += header.Render(fmt.Sprintf("%s (%d)", m.sheets[result.sheet].Name, count)) + "\n"
+		}
+
+		line := fmt.Sprintf("%-7s %s", ui.CellRef(result.cell.Row, result.cell.Col), ui.TruncateToWidth(result.cell.Value, 24))
+		context := ui.Truncate(m.rowContext(result), 36)
+		if i == m.resultsCursor {
+			content += selected.Render("→ "+line) + dim.Render(" "+context) + "\n"
+		} else {
+			content += text.Render("  "+line) + dim.Render(" "+context) + "\n"
+		}
+	}
+
+	content += "\n" + dim.Italic(true).Render(strings.Join([]string{
+		fmt.Sprintf("%d/%d", m.resultsCursor+1, len(m.searchResults)), "↑/↓ select", "Enter jump", "Esc close",
+	}, " • "))
+
+	return m.styles.Modal.Width(80).Render(content)
+}
+
+// rowContext joins the values around a result in its row
+func (m *Model) rowContext(result searchResult) string {
+	sheet := m.sheets[result.sheet]
+	cellValue := m.cellValues(result.sheet)
+	var parts []string
+	for col := ui.Max(0, result.cell.Col-2); col <= result.cell.Col+2 && col < sheet.MaxCols; col++ {
+		if col == result.cell.Col {
+			continue
+		}
+		if value := cellValue(result.cell.Row, col); value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, " │ ")
+}
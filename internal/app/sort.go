@@ -0,0 +1,251 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vex/internal/loader"
+	"github.com/vex/internal/theme"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+// sortKey is one column of a multi-key sort
+type sortKey struct {
+	col  int
+	desc bool
+}
+
+// sortEdit reorders a block of rows. order[i] is the offset within the
+// block of the row that ends up at offset i.
+type sortEdit struct {
+	sheet int
+	name  string
+	from  int
+	order []int
+}
+
+func (c *sortEdit) apply(m *Model) {
+	c.permute(m, c.order)
+}
+
+func (c *sortEdit) revert(m *Model) {
+	inverse := make([]int, len(c.order))
+	for i, src := range c.order {
+		inverse[src] = i
+	}
+	c.permute(m, inverse)
+}
+
+func (c *sortEdit) label() string { return c.name }
+
+func (c *sortEdit) cost() int { return len(c.order) }
+
+func (c *sortEdit) sheets() []int { return []int{c.sheet} }
+
+// permute rearranges the block and records the new order in the save
+// journal, so row styles move with their rows when saved
+func (c *sortEdit) permute(m *Model, order []int) {
+	sheet := &m.sheets[c.sheet]
+	rows := make([][]models.Cell, len(order))
+	for i, src := range order {
+		rows[i] = sheet.Rows[c.from+src]
+	}
+	copy(sheet.Rows[c.from:], rows)
+	sheet.Renumber()
+
+	sheet.Edits = append(sheet.Edits, models.StructuralEdit{
+		Kind:  models.EditPermuteRows,
+		Index: c.from,
+		Count: len(order),
+		Order: append([]int(nil), order...),
+	})
+	sheet.Dirty = true
+	m.moveTo(position{sheet: c.sheet, row: c.from, col: -1})
+}
+
+// sortRange returns the block of rows a sort covers: the selected rows,
+// or every row below the header when it is pinned
+func (m *Model) sortRange() (from, to int) {
+	sheet := m.sheets[m.currentSheet]
+	if m.isSelecting {
+		from, count := m.targetRows()
+		return from, ui.Min(from+count, len(sheet.Rows))
+	}
+	if m.sortHeader {
+		return ui.Min(m.headerRow()+1, len(sheet.Rows)), len(sheet.Rows)
+	}
+	return 0, len(sheet.Rows)
+}
+
+// sortRows sorts the rows in range by the given keys as one undoable action.
+// Like Excel, only the rows the autofilter shows are sorted; hidden rows
+// keep their places.
+func (m *Model) sortRows(keys []sortKey) {
+	from, to := m.sortRange()
+	var shown []int
+	for row := from; row < to; row++ {
+		if !m.rowHidden(row) {
+			shown = append(shown, row)
+		}
+	}
+	if len(shown) < 2 || len(keys) == 0 {
+		m.status = models.StatusMsg{Message: "Nothing to sort", Type: models.StatusWarning}
+		return
+	}
+	value := m.cellValues(m.currentSheet)
+
+	sorted := append([]int(nil), shown...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		ra, rb := sorted[a], sorted[b]
+		for _, k := range keys {
+			va, vb := value(ra, k.col), value(rb, k.col)
+			cmp := loader.CompareValues(va, vb)
+			// Blanks stay last whichever way the column sorts
+			if k.desc && strings.TrimSpace(va) != "" && strings.TrimSpace(vb) != "" {
+				cmp = -cmp
+			}
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+
+	order := make([]int, to-from)
+	for i := range order {
+		order[i] = i
+	}
+	changed := false
+	for i, row := range shown {
+		order[row-from] = sorted[i] - from
+		changed = changed || sorted[i] != row
+	}
+	if !changed {
+		m.status = models.StatusMsg{Message: "Already sorted", Type: models.StatusInfo}
+		return
+	}
+
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = m.sortKeyLabel(k)
+	}
+	m.isSelecting = false
+	m.execute(&sortEdit{
+		sheet: m.currentSheet,
+		name:  fmt.Sprintf("Sort rows %d-%d by %s", from+1, to, strings.Join(names, ", ")),
+		from:  from,
+		order: order,
+	})
+}
+
+// sortKeyLabel names a sort key by its header when the header is pinned
+func (m *Model) sortKeyLabel(k sortKey) string {
+	name := ui.ColIndexToLetter(k.col)
+	if m.sortHeader {
+		if header := strings.TrimSpace(m.sheets[m.currentSheet].CellAt(m.headerRow(), k.col).Value); header != "" {
+			name = header
+		}
+	}
+	if k.desc {
+		return name + " ↓"
+	}
+	return name + " ↑"
+}
+
+// startSort opens the multi-key sort dialog
+func (m Model) startSort() (tea.Model, tea.Cmd) {
+	if len(m.sortKeys) == 0 {
+		m.sortKeys = []sortKey{{col: m.cursorCol}}
+	}
+	m.sortCursor = 0
+	m.mode = models.ModeSort
+	return m, nil
+}
+
+// updateSort handles the multi-key sort dialog
+func (m Model) updateSort(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	maxCol := ui.Max(0, m.sheets[m.currentSheet].MaxCols-1)
+
+	switch msg.String() {
+	case "esc", "q":
+		m.mode = models.ModeNormal
+	case "up", "k":
+		if m.sortCursor > 0 {
+			m.sortCursor--
+		}
+	case "down", "j":
+		if m.sortCursor < len(m.sortKeys)-1 {
+			m.sortCursor++
+		}
+	case "left", "h":
+		if len(m.sortKeys) > 0 && m.sortKeys[m.sortCursor].col > 0 {
+			m.sortKeys[m.sortCursor].col--
+		}
+	case "right", "l":
+		if len(m.sortKeys) > 0 && m.sortKeys[m.sortCursor].col < maxCol {
+			m.sortKeys[m.sortCursor].col++
+		}
+	case " ", "d":
+		if len(m.sortKeys) > 0 {
+			m.sortKeys[m.sortCursor].desc = !m.sortKeys[m.sortCursor].desc
+		}
+	case "a":
+		m.sortKeys = append(m.sortKeys, sortKey{col: m.cursorCol})
+		m.sortCursor = len(m.sortKeys) - 1
+	case "x":
+		if len(m.sortKeys) > 0 {
+			m.sortKeys = append(m.sortKeys[:m.sortCursor], m.sortKeys[m.sortCursor+1:]...)
+			m.sortCursor = ui.Max(0, ui.Min(m.sortCursor, len(m.sortKeys)-1))
+		}
+	case "p":
+		m.sortHeader = !m.sortHeader
+	case "enter":
+		m.mode = models.ModeNormal
+		m.sortRows(m.sortKeys)
+	}
+	return m, nil
+}
+
+// renderSort renders the multi-key sort dialog
+func (m Model) renderSort() string {
+	t := theme.GetCurrentTheme()
+	dim := lipgloss.NewStyle().Foreground(t.DimText)
+	text := lipgloss.NewStyle().Foreground(t.Text)
+	selected := lipgloss.NewStyle().Foreground(t.Accent).Bold(true)
+
+	content := m.styles.ModalTitle.Render("↕ Sort") + "\n\n"
+
+	from, to := m.sortRange()
+	content += m.styles.ModalKey.Render("Rows: ") + m.styles.ModalValue.Render(fmt.Sprintf("%d-%d", from+1, to)) + "\n\n"
+
+	if len(m.sortKeys) == 0 {
+		content += dim.Render("  No sort keys, press a to add one") + "\n"
+	}
+	for i, k := range m.sortKeys {
+		prefix := "then by "
+		if i == 0 {
+			prefix = "Sort by "
+		}
+		line := fmt.Sprintf("%d. %s%s", i+1, prefix, m.sortKeyLabel(k))
+		if i == m.sortCursor {
+			content += selected.Render("→ "+line) + "\n"
+		} else {
+			content += text.Render("  "+line) + "\n"
+		}
+	}
+
+	toggle := dim.Render("[ ]")
+	if m.sortHeader {
+		toggle = lipgloss.NewStyle().Foreground(t.Success).Render("[x]")
+	}
+	content += "\n" + toggle + m.styles.ModalValue.Render(" p  Keep header row pinned") + "\n\n"
+	content += dim.Italic(true).Render(strings.Join([]string{
+		"←/→ column", "space asc/desc", "a add", "x remove", "Enter sort", "Esc cancel",
+	}, " • "))
+
+	return m.styles.Modal.Width(64).Render(content)
+}
@@ -0,0 +1,249 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vex/internal/stats"
+	"github.com/vex/internal/theme"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+const (
+	// statsAsyncValues is the column size from which statistics are
+	// computed in the background
+	statsAsyncValues = 50000
+	// statsBins is the number of histogram bars
+	statsBins = 40
+	// statsTopN is the number of most frequent values shown
+	statsTopN = 5
+)
+
+// statsDoneMsg delivers statistics computed in the background
+type statsDoneMsg struct {
+	seq     int
+	summary stats.Summary
+	err     error
+}
+
+// headerRow returns the header row of the current sheet, which statistics
+// and filters leave out: the autofilter's header or the first row
+func (m *Model) headerRow() int {
+	return m.headerRowOf(m.currentSheet)
+}
+
+// headerRowOf returns the header row of a sheet
+func (m *Model) headerRowOf(sheet int) int {
+	if f := m.sheets[sheet].Filter; f != nil {
+		return f.HeaderRow
+	}
+	return 0
+}
+
+// headerRows returns the header row of every sheet
+func (m *Model) headerRows() []int {
+	headers := make([]int, len(m.sheets))
+	for i := range m.sheets {
+		headers[i] = m.headerRowOf(i)
+	}
+	return headers
+}
+
+// columnValues returns the values of a column below the header row,
+// leaving out rows hidden by the autofilter
+func (m *Model) columnValues(col int) []string {
+	sheet := m.sheets[m.currentSheet]
+	value := m.cellValues(m.currentSheet)
+	var values []string
+	for row := m.headerRow() + 1; row < sheet.MaxRows; row++ {
+		if !m.rowHidden(row) {
+			values = append(values, value(row, col))
+		}
+	}
+	return values
+}
+
+// updateSelectionStats recomputes the aggregate of the selected cells shown
+// in the status bar, leaving out rows hidden by the autofilter
+func (m *Model) updateSelectionStats() {
+	m.selectAgg = stats.Aggregate{}
+	if !m.isSelecting {
+		return
+	}
+	value := m.cellValues(m.currentSheet)
+	startRow, startCol, endRow, endCol := m.copyBounds()
+	for row := startRow; row <= endRow; row++ {
+		if m.rowHidden(row) {
+			continue
+		}
+		for col := startCol; col <= endCol; col++ {
+			m.selectAgg.Add(value(row, col))
+		}
+	}
+}
+
+// startStats opens the statistics panel for the cursor column, computing
+// large columns in the background
+func (m Model) startStats() (tea.Model, tea.Cmd) {
+	if m.statsCancel != nil {
+		m.statsCancel()
+		m.statsCancel = nil
+	}
+	m.mode = models.ModeStats
+	m.statsCol = m.cursorCol
+	m.statsSeq++
+	m.statsSummary = nil
+
+	values := m.columnValues(m.statsCol)
+	m.statsTotal = len(values)
+	if len(values) < statsAsyncValues {
+		summary, _ := stats.Summarize(context.Background(), values, statsBins, statsTopN)
+		m.statsSummary = &summary
+		return m, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.statsCancel = cancel
+	seq := m.statsSeq
+	return m, func() tea.Msg {
+		summary, err := stats.Summarize(ctx, values, statsBins, statsTopN)
+		return statsDoneMsg{seq: seq, summary: summary, err: err}
+	}
+}
+
+// finishStats shows statistics computed in the background unless the
+// panel has since been closed or moved to another column
+func (m Model) finishStats(msg statsDoneMsg) (tea.Model, tea.Cmd) {
+	if msg.seq != m.statsSeq {
+		return m, nil
+	}
+	m.statsCancel = nil
+	if msg.err != nil {
+		if !errors.Is(msg.err, context.Canceled) {
+			m.status = models.StatusMsg{Message: msg.err.Error(), Type: models.StatusError}
+		}
+		return m, nil
+	}
+	m.statsSummary = &msg.summary
+	return m, nil
+}
+
+// updateStats handles the statistics panel
+func (m Model) updateStats(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "#":
+		if m.statsCancel != nil {
+			m.statsCancel()
+			m.statsCancel = nil
+		}
+		m.statsSeq++
+		m.mode = models.ModeNormal
+	case "left", "h":
+		if m.statsCol > 0 {
+			m.moveTo(position{sheet: m.currentSheet, row: -1, col: m.statsCol - 1})
+			return m.startStats()
+		}
+	case "right", "l":
+		if m.statsCol < m.sheets[m.currentSheet].MaxCols-1 {
+			m.moveTo(position{sheet: m.currentSheet, row: -1, col: m.statsCol + 1})
+			return m.startStats()
+		}
+	}
+	return m, nil
+}
+
+// renderStats renders the statistics panel
+func (m Model) renderStats() string {
+	t := theme.GetCurrentTheme()
+	dim := lipgloss.NewStyle().Foreground(t.DimText)
+	text := lipgloss.NewStyle().Foreground(t.Text)
+	accent := lipgloss.NewStyle().Foreground(t.Accent)
+
+	sheet := m.sheets[m.currentSheet]
+	name := ui.ColIndexToLetter(m.statsCol)
+	if h := strings.TrimSpace(sheet.CellAt(m.headerRow(), m.statsCol).Value); h != "" {
+		name += " (" + ui.Truncate(h, 30) + ")"
+	}
+	content := m.styles.ModalTitle.Render("📊 Column "+name) + "\n\n"
+
+	s := m.statsSummary
+	if s == nil {
+		content += dim.Render(fmt.Sprintf("Computing statistics for %d rows…", m.statsTotal)) + "\n\n"
+		content += dim.Italic(true).Render("Esc cancel")
+		return m.styles.Modal.Width(72).Render(content)
+	}
+
+	field := func(label, value string) string {
+		return m.styles.ModalKey.Render(fmt.Sprintf("%-10s", label)) + m.styles.ModalValue.Render(fmt.Sprintf("%-14s", value))
+	}
+	content += field("Count", strconv.Itoa(s.Count)) + field("Blanks", strconv.Itoa(s.Blanks)) + field("Distinct", strconv.Itoa(s.Distinct)) + "\n"
+	content += field("Min", ui.Truncate(s.Min, 14)) + field("Max", ui.Truncate(s.Max, 14)) + "\n"
+	if sheet.Filter != nil {
+		content += dim.Render("  Rows hidden by the filter are left out") + "\n"
+	}
+
+	if s.Numeric > 0 {
+		content += "\n" + accent.Bold(true).Render(fmt.Sprintf("Numbers (%d)", s.Numeric)) + "\n"
+		content += field("Sum", formatStat(s.Sum)) + field("Mean", formatStat(s.Mean)) + field("Median", formatStat(s.Median)) + "\n"
+		content += field("Std dev", formatStat(s.StdDev)) + field("Min", formatStat(s.NumMin)) + field("Max", formatStat(s.NumMax)) + "\n"
+
+		var pcts []string
+		for i, p := range stats.Percentiles {
+			pcts = append(pcts, dim.Render(fmt.Sprintf("P%g ", p))+text.Render(formatStat(s.Percentiles[i])))
+		}
+		content += strings.Join(pcts, "  ") + "\n\n"
+		content += renderHistogram(s.Histogram, t.Accent) + "\n"
+		content += dim.Render(fmt.Sprintf("%-*s%s", ui.Max(1, len(s.Histogram)-len(formatStat(s.NumMax))), formatStat(s.NumMin), formatStat(s.NumMax))) + "\n"
+	}
+
+	if len(s.Top) > 0 {
+		content += "\n" + accent.Bold(true).Render("Most frequent") + "\n"
+		for _, vc := range s.Top {
+			bar := strings.Repeat("█", ui.Max(1, vc.Count*20/s.Top[0].Count))
+			content += text.Render(fmt.Sprintf("  %-30s", ui.Truncate(vc.Value, 30))) +
+				dim.Render(fmt.Sprintf("%6d ", vc.Count)) + accent.Render(bar) + "\n"
+		}
+	}
+
+	content += "\n" + dim.Italic(true).Render(strings.Join([]string{"←/→ column", "Esc close"}, " • "))
+	return m.styles.Modal.Width(72).Render(content)
+}
+
+// renderHistogram draws bin counts as a bar of block characters scaled
+// from zero, leaving empty bins blank
+func renderHistogram(bins []int, color lipgloss.Color) string {
+	chars := []rune{'▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+	peak := 0
+	for _, n := range bins {
+		peak = ui.Max(peak, n)
+	}
+
+	var b strings.Builder
+	for _, n := range bins {
+		if n == 0 || peak == 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		b.WriteRune(chars[n*(len(chars)-1)/peak])
+	}
+	return lipgloss.NewStyle().Foreground(color).Render(b.String())
+}
+
+// formatStat formats a statistic, dropping insignificant decimals
+func formatStat(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+	if math.Abs(v) >= 1e15 || math.Abs(v) < 1e-4 {
+		return strconv.FormatFloat(v, 'g', 6, 64)
+	}
+	s := strconv.FormatFloat(v, 'f', 4, 64)
+	return strings.TrimRight(strings.TrimRight(s, "0"), ".")
+}
@@ -0,0 +1,339 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/vex/internal/formula"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+// structKind identifies a row or column operation
+type structKind int
+
+const (
+	structInsertRows structKind = iota
+	structDeleteRows
+	structInsertCols
+	structDeleteCols
+	structMoveRow
+	structDuplicateRow
+)
+
+// formulaChange records a formula rewritten by a structural edit
+type formulaChange struct {
+	sheet  int
+	row    int
+	col    int
+	before string
+}
+
+// structEdit inserts, deletes, moves or duplicates rows or columns. For
+// structMoveRow, count is the direction the row moves (-1 or 1).
+type structEdit struct {
+	sheet    int
+	name     string
+	kind     structKind
+	at       int
+	count    int
+	removed  [][]models.Cell
+	formulas []formulaChange         // Formulas rewritten by the last apply
+	computed []models.ComputedColumn // Computed columns before a row or column delete
+	formats  []models.FormatRule     // Conditional formats before a row or column delete
+	filter   *models.AutoFilter      // Autofilter before a row or column delete
+	journal  [2]int                  // Save journal entries written by apply
+	saves    int                     // Saves of the workbook before apply
+}
+
+func (c *structEdit) apply(m *Model) {
+	sheet := &m.sheets[c.sheet]
+	c.saves, c.journal[0] = m.saves, len(sheet.Edits)
+	c.formulas = nil
+
+	switch c.kind {
+	case structInsertRows:
+		c.remapFormulas(m, formula.Rows, formula.Shift(c.at, c.count))
+		sheet.InsertRows(c.at, c.count)
+		c.record(sheet, models.StructuralEdit{Kind: models.EditInsertRows, Index: c.at, Count: c.count})
+		m.moveTo(position{sheet: c.sheet, row: c.at, col: -1})
+
+	case structDeleteRows:
+		c.remapFormulas(m, formula.Rows, formula.Shift(c.at, -c.count))
+		c.computed = append([]models.ComputedColumn(nil), sheet.Computed...)
+		c.formats, c.filter = sheet.Formats, sheet.Filter
+		c.removed = sheet.DeleteRows(c.at, c.count)
+		c.record(sheet, models.StructuralEdit{Kind: models.EditDeleteRows, Index: c.at, Count: c.count})
+		m.moveTo(position{sheet: c.sheet, row: c.at, col: -1})
+
+	case structInsertCols:
+		c.remapFormulas(m, formula.Cols, formula.Shift(c.at, c.count))
+		sheet.InsertCols(c.at, c.count)
+		c.record(sheet, models.StructuralEdit{Kind: models.EditInsertCols, Index: c.at, Count: c.count})
+		m.moveTo(position{sheet: c.sheet, row: -1, col: c.at})
+
+	case structDeleteCols:
+		c.remapFormulas(m, formula.Cols, formula.Shift(c.at, -c.count))
+		c.computed = append([]models.ComputedColumn(nil), sheet.Computed...)
+		c.formats, c.filter = sheet.Formats, sheet.Filter
+		c.removed = sheet.DeleteCols(c.at, c.count)
+		c.record(sheet, models.StructuralEdit{Kind: models.EditDeleteCols, Index: c.at, Count: c.count})
+		m.moveTo(position{sheet: c.sheet, row: -1, col: c.at})
+
+	case structMoveRow:
+		c.remapFormulas(m, formula.Rows, formula.Swap(c.at, c.at+c.count))
+		sheet.SwapRows(c.at, c.at+c.count)
+		for _, e := range swapEdits(c.at, c.at+c.count) {
+			c.record(sheet, e)
+		}
+		m.moveTo(position{sheet: c.sheet, row: c.at + c.count, col: -1})
+
+	case structDuplicateRow:
+		c.remapFormulas(m, formula.Rows, formula.Shift(c.at+1, 1))
+		sheet.InsertRows(c.at+1, 1)
+		if c.at < len(sheet.Rows) {
+			sheet.Rows[c.at+1] = append([]models.Cell(nil), sheet.Rows[c.at]...)
+			sheet.Renumber()
+		}
+		c.record(sheet, models.StructuralEdit{Kind: models.EditCopyRow, Index: c.at, To: c.at + 1})
+		m.moveTo(position{sheet: c.sheet, row: c.at + 1, col: -1})
+	}
+	c.journal[1] = len(sheet.Edits)
+}
+
+func (c *structEdit) revert(m *Model) {
+	sheet := &m.sheets[c.sheet]
+
+	// Take the entries of apply back out of the save journal while they are
+	// still its last ones, so saving does not replay a change and its
+	// inverse and lose what vex does not model, such as row styles. After
+	// a save the inverse change is recorded instead.
+	unrecord := m.saves == c.saves && len(sheet.Edits) == c.journal[1]
+	var inverse []models.StructuralEdit
+
+	switch c.kind {
+	case structInsertRows:
+		sheet.DeleteRows(c.at, c.count)
+		inverse = []models.StructuralEdit{{Kind: models.EditDeleteRows, Index: c.at, Count: c.count}}
+
+	case structDeleteRows:
+		sheet.RestoreRows(c.at, c.removed)
+		sheet.Computed = c.computed
+		sheet.Formats, sheet.Filter = c.formats, c.filter
+		inverse = []models.StructuralEdit{{Kind: models.EditInsertRows, Index: c.at, Count: c.count}}
+
+	case structInsertCols:
+		sheet.DeleteCols(c.at, c.count)
+		inverse = []models.StructuralEdit{{Kind: models.EditDeleteCols, Index: c.at, Count: c.count}}
+
+	case structDeleteCols:
+		sheet.RestoreCols(c.at, c.count, c.removed)
+		sheet.Computed = c.computed
+		sheet.Formats, sheet.Filter = c.formats, c.filter
+		inverse = []models.StructuralEdit{{Kind: models.EditInsertCols, Index: c.at, Count: c.count}}
+
+	case structMoveRow:
+		sheet.SwapRows(c.at+c.count, c.at)
+		inverse = swapEdits(c.at+c.count, c.at)
+
+	case structDuplicateRow:
+		sheet.DeleteRows(c.at+1, 1)
+		inverse = []models.StructuralEdit{{Kind: models.EditDeleteRows, Index: c.at + 1, Count: 1}}
+	}
+
+	if unrecord {
+		sheet.Edits = sheet.Edits[:c.journal[0]]
+		sheet.Dirty = true
+	} else {
+		for _, e := range inverse {
+			c.record(sheet, e)
+		}
+	}
+
+	// Restore rewritten formulas, latest change first
+	for i := len(c.formulas) - 1; i >= 0; i-- {
+		fc := c.formulas[i]
+		m.sheets[fc.sheet].EnsureCell(fc.row, fc.col).Formula = fc.before
+	}
+
+	if c.kind == structInsertCols || c.kind == structDeleteCols {
+		m.moveTo(position{sheet: c.sheet, row: -1, col: c.at})
+	} else {
+		m.moveTo(position{sheet: c.sheet, row: c.at, col: -1})
+	}
+}
+
+func (c *structEdit) label() string { return c.name }
+
+// sheets returns the edited sheet and those whose formulas were rewritten
+func (c *structEdit) sheets() []int {
+	sheets := []int{c.sheet}
+	for _, fc := range c.formulas {
+		if fc.sheet != c.sheet && fc.sheet != sheets[len(sheets)-1] {
+			sheets = append(sheets, fc.sheet)
+		}
+	}
+	return sheets
+}
+
+func (c *structEdit) cost() int {
+	n := 1
+	for _, cells := range c.removed {
+		n += len(cells)
+	}
+	return n + len(c.formulas)
+}
+
+// swapEdits returns the save journal entries of swapping row from with its
+// neighbour to: a copy followed by a delete, so the file keeps row styles
+func swapEdits(from, to int) []models.StructuralEdit {
+	if to > from {
+		return []models.StructuralEdit{
+			{Kind: models.EditCopyRow, Index: from, To: to + 1},
+			{Kind: models.EditDeleteRows, Index: from, Count: 1},
+		}
+	}
+	return []models.StructuralEdit{
+		{Kind: models.EditCopyRow, Index: from, To: to},
+		{Kind: models.EditDeleteRows, Index: from + 1, Count: 1},
+	}
+}
+
+// remapFormulas rewrites references to the edited sheet in every formula
+// of the workbook, remembering the original text for undo
+func (c *structEdit) remapFormulas(m *Model, axis formula.Axis, mapFn formula.Mapper) {
+	target := m.sheets[c.sheet].Name
+	for s := range m.sheets {
+		sheet := &m.sheets[s]
+		for r, row := range sheet.Rows {
+			for col, cell := range row {
+				if cell.Formula == "" {
+					continue
+				}
+				updated := formula.Remap(cell.Formula, sheet.Name, target, axis, mapFn)
+				if updated == cell.Formula {
+					continue
+				}
+				c.formulas = append(c.formulas, formulaChange{sheet: s, row: r, col: col, before: cell.Formula})
+				row[col].Formula = updated
+				sheet.Dirty = true
+			}
+		}
+	}
+}
+
+// record appends a structural edit to the sheet's save journal
+func (c *structEdit) record(sheet *models.Sheet, edit models.StructuralEdit) {
+	sheet.Edits = append(sheet.Edits, edit)
+	sheet.Dirty = true
+}
+
+// targetRows returns the selected rows, or the cursor row without a selection
+func (m *Model) targetRows() (start, count int) {
+	if m.isSelecting {
+		start = ui.Min(m.selectStart[0], m.selectEnd[0])
+		return start, abs(m.selectEnd[0]-m.selectStart[0]) + 1
+	}
+	return m.cursorRow, 1
+}
+
+// targetCols returns the selected columns, or the cursor column without a selection
+func (m *Model) targetCols() (start, count int) {
+	if m.isSelecting {
+		start = ui.Min(m.selectStart[1], m.selectEnd[1])
+		return start, abs(m.selectEnd[1]-m.selectStart[1]) + 1
+	}
+	return m.cursorCol, 1
+}
+
+// insertRows inserts rows above or below the cursor row or selection
+func (m *Model) insertRows(below bool) {
+	at, count := m.targetRows()
+	if below && m.sheets[m.currentSheet].MaxRows > 0 {
+		at += count
+	}
+	m.isSelecting = false
+	m.execute(&structEdit{
+		sheet: m.currentSheet,
+		name:  fmt.Sprintf("Insert %d row(s) at %d", count, at+1),
+		kind:  structInsertRows,
+		at:    at,
+		count: count,
+	})
+}
+
+// deleteRows deletes the cursor row or the selected rows
+func (m *Model) deleteRows() {
+	at, count := m.targetRows()
+	sheet := m.sheets[m.currentSheet]
+	if at >= sheet.MaxRows {
+		return
+	}
+	count = ui.Min(count, sheet.MaxRows-at)
+	m.isSelecting = false
+	m.execute(&structEdit{
+		sheet: m.currentSheet,
+		name:  fmt.Sprintf("Delete %d row(s) at %d", count, at+1),
+		kind:  structDeleteRows,
+		at:    at,
+		count: count,
+	})
+}
+
+// insertCols inserts columns before the cursor column or selection
+func (m *Model) insertCols() {
+	at, count := m.targetCols()
+	m.isSelecting = false
+	m.execute(&structEdit{
+		sheet: m.currentSheet,
+		name:  fmt.Sprintf("Insert %d column(s) at %s", count, ui.ColIndexToLetter(at)),
+		kind:  structInsertCols,
+		at:    at,
+		count: count,
+	})
+}
+
+// deleteCols deletes the cursor column or the selected columns
+func (m *Model) deleteCols() {
+	at, count := m.targetCols()
+	sheet := m.sheets[m.currentSheet]
+	if at >= sheet.MaxCols {
+		return
+	}
+	count = ui.Min(count, sheet.MaxCols-at)
+	m.isSelecting = false
+	m.execute(&structEdit{
+		sheet: m.currentSheet,
+		name:  fmt.Sprintf("Delete %d column(s) at %s", count, ui.ColIndexToLetter(at)),
+		kind:  structDeleteCols,
+		at:    at,
+		count: count,
+	})
+}
+
+// moveRow moves the cursor row up (dir -1) or down (dir 1)
+func (m *Model) moveRow(dir int) {
+	to := m.cursorRow + dir
+	if to < 0 || to >= m.sheets[m.currentSheet].MaxRows {
+		return
+	}
+	m.execute(&structEdit{
+		sheet: m.currentSheet,
+		name:  fmt.Sprintf("Move row %d to %d", m.cursorRow+1, to+1),
+		kind:  structMoveRow,
+		at:    m.cursorRow,
+		count: dir,
+	})
+}
+
+// duplicateRow inserts a copy of the cursor row below it
+func (m *Model) duplicateRow() {
+	if m.cursorRow >= m.sheets[m.currentSheet].MaxRows {
+		return
+	}
+	m.execute(&structEdit{
+		sheet: m.currentSheet,
+		name:  fmt.Sprintf("Duplicate row %d", m.cursorRow+1),
+		kind:  structDuplicateRow,
+		at:    m.cursorRow,
+	})
+}
@@ -2,13 +2,12 @@ package app
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 
-	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/vex/internal/clipboard"
 	"github.com/vex/internal/loader"
 	"github.com/vex/internal/ui"
 	"github.com/vex/pkg/models"
@@ -19,8 +18,19 @@ func (m Model) Init() tea.Cmd {
 	return nil
 }
 
-// Update handles messages and updates the model
+// Update handles messages and updates the model, repeating the active
+// search when a message changed the searched sheets
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	if next, ok := model.(Model); ok && next.searchStale {
+		cmd = tea.Batch(cmd, next.refreshSearch())
+		return next, cmd
+	}
+	return model, cmd
+}
+
+// update dispatches a message to the handler of the current mode
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -28,6 +38,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.help.Width = msg.Width
 		return m, nil
 
+	case searchTickMsg:
+		return m.startLiveSearch(msg)
+
+	case searchDoneMsg:
+		return m.finishLiveSearch(msg)
+
+	case statsDoneMsg:
+		return m.finishStats(msg)
+
 	case tea.KeyMsg:
 		switch m.mode {
 		case models.ModeSearch:
@@ -44,6 +63,42 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateChart(msg)
 		case models.ModeSelectRange:
 			return m.updateSelectRange(msg)
+		case models.ModeEdit:
+			return m.updateEdit(msg)
+		case models.ModeConfirmQuit:
+			return m.updateConfirmQuit(msg)
+		case models.ModeHistory:
+			return m.updateHistory(msg)
+		case models.ModePaste:
+			return m.updatePaste(msg)
+		case models.ModeCopyAs:
+			return m.updateCopyAs(msg)
+		case models.ModeReplace:
+			return m.updateReplace(msg)
+		case models.ModeSearchResults:
+			return m.updateSearchResults(msg)
+		case models.ModeFinder:
+			return m.updateFinder(msg)
+		case models.ModeSort:
+			return m.updateSort(msg)
+		case models.ModeFilter:
+			return m.updateFilter(msg)
+		case models.ModeStats:
+			return m.updateStats(msg)
+		case models.ModePivot:
+			return m.updatePivot(msg)
+		case models.ModeQuery:
+			return m.updateQuery(msg)
+		case models.ModeComputed:
+			return m.updateComputed(msg)
+		case models.ModeDuplicates:
+			return m.updateDuplicates(msg)
+		case models.ModeProfile:
+			return m.updateProfile(msg)
+		case models.ModeOutliers:
+			return m.updateOutliers(msg)
+		case models.ModeFormats:
+			return m.updateFormats(msg)
 		default:
 			return m.updateNormal(msg)
 		}
@@ -65,17 +120,21 @@ func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	switch {
 	case key.Matches(msg, m.keys.Quit):
+		if m.isDirty() {
+			m.mode = models.ModeConfirmQuit
+			return m, nil
+		}
 		return m, tea.Quit
 
 	case key.Matches(msg, m.keys.Up):
-		if m.cursorRow > 0 {
-			m.cursorRow--
+		if row := m.stepRows(m.cursorRow, -1); row != m.cursorRow {
+			m.cursorRow = row
 			m.adjustViewport()
 		}
 
 	case key.Matches(msg, m.keys.Down):
-		if m.cursorRow < sheet.MaxRows-1 {
-			m.cursorRow++
+		if row := m.stepRows(m.cursorRow, 1); row != m.cursorRow {
+			m.cursorRow = row
 			m.adjustViewport()
 		}
 
@@ -93,12 +152,12 @@ func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case key.Matches(msg, m.keys.PageDown):
 		visibleRows := ui.Max(1, m.height-9)
-		m.cursorRow = ui.Min(m.cursorRow+visibleRows, sheet.MaxRows-1)
+		m.cursorRow = m.stepRows(m.cursorRow, visibleRows)
 		m.adjustViewport()
 
 	case key.Matches(msg, m.keys.PageUp):
 		visibleRows := ui.Max(1, m.height-9)
-		m.cursorRow = ui.Max(m.cursorRow-visibleRows, 0)
+		m.cursorRow = m.stepRows(m.cursorRow, -visibleRows)
 		m.adjustViewport()
 
 	case key.Matches(msg, m.keys.Home):
@@ -121,6 +180,7 @@ func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.currentSheet < len(m.sheets)-1 {
 			m.currentSheet++
 			m.resetView()
+			m.refreshFormatter()
 			m.status = models.StatusMsg{
 				Message: fmt.Sprintf("→ %s", m.sheets[m.currentSheet].Name),
 				Type:    models.StatusInfo,
@@ -131,6 +191,7 @@ func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.currentSheet > 0 {
 			m.currentSheet--
 			m.resetView()
+			m.refreshFormatter()
 			m.status = models.StatusMsg{
 				Message: fmt.Sprintf("← %s", m.sheets[m.currentSheet].Name),
 				Type:    models.StatusInfo,
@@ -139,36 +200,41 @@ func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case key.Matches(msg, m.keys.Search):
 		m.mode = models.ModeSearch
+		m.searchOrigin = m.currentPosition()
+		m.resetPromptHistory()
 		m.searchInput.Focus()
 		m.searchInput.SetValue(m.searchQuery)
 		m.searchInput.CursorEnd()
 		return m, textinput.Blink
 
 	case key.Matches(msg, m.keys.NextResult):
-		if len(m.searchResults) > 0 {
+		if m.outlierNav && len(m.outlierCells) > 0 {
+			m.stepOutlier(1)
+		} else if len(m.searchResults) > 0 {
 			m.searchIndex = (m.searchIndex + 1) % len(m.searchResults)
 			m.jumpToSearchResult()
-			m.status = models.StatusMsg{
-				Message: fmt.Sprintf("Match %d/%d", m.searchIndex+1, len(m.searchResults)),
-				Type:    models.StatusInfo,
-			}
 		}
 
 	case key.Matches(msg, m.keys.PrevResult):
-		if len(m.searchResults) > 0 {
+		if m.outlierNav && len(m.outlierCells) > 0 {
+			m.stepOutlier(-1)
+		} else if len(m.searchResults) > 0 {
 			m.searchIndex = (m.searchIndex - 1 + len(m.searchResults)) % len(m.searchResults)
 			m.jumpToSearchResult()
-			m.status = models.StatusMsg{
-				Message: fmt.Sprintf("Match %d/%d", m.searchIndex+1, len(m.searchResults)),
-				Type:    models.StatusInfo,
-			}
 		}
 
+	case key.Matches(msg, m.keys.SearchList):
+		if len(m.searchResults) == 0 {
+			m.status = models.StatusMsg{Message: "No search results", Type: models.StatusWarning}
+			return m, nil
+		}
+		m.mode = models.ModeSearchResults
+		m.resultsCursor = m.searchIndex
+		return m, nil
+
 	case key.Matches(msg, m.keys.ClearSearch):
 		if m.searchQuery != "" {
-			m.searchQuery = ""
-			m.searchResults = nil
-			m.searchIndex = 0
+			m.clearSearch()
 			m.status = models.StatusMsg{Message: "Search cleared", Type: models.StatusInfo}
 		}
 
@@ -176,12 +242,22 @@ func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.mode = models.ModeDetail
 		return m, nil
 
+	case key.Matches(msg, m.keys.Finder):
+		return m.startFinder()
+
 	case key.Matches(msg, m.keys.Jump):
 		m.mode = models.ModeJump
+		m.resetPromptHistory()
 		m.jumpInput.Focus()
 		m.jumpInput.SetValue("")
 		return m, textinput.Blink
 
+	case key.Matches(msg, m.keys.JumpBack):
+		m.jumpHistoryBack()
+
+	case key.Matches(msg, m.keys.JumpForward):
+		m.jumpHistoryForward()
+
 	case key.Matches(msg, m.keys.ToggleForm):
 		m.showFormulas = !m.showFormulas
 		if m.showFormulas {
@@ -196,8 +272,96 @@ func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keys.CopyRow):
 		m.copyRow()
 
+	case key.Matches(msg, m.keys.Edit):
+		return m.startEdit()
+
+	case key.Matches(msg, m.keys.Save):
+		m.saveFile()
+
+	case key.Matches(msg, m.keys.Undo):
+		m.undo()
+
+	case key.Matches(msg, m.keys.Redo):
+		m.redo()
+
+	case key.Matches(msg, m.keys.InsertRow):
+		m.insertRows(true)
+
+	case key.Matches(msg, m.keys.InsertAbove):
+		m.insertRows(false)
+
+	case key.Matches(msg, m.keys.DeleteRow):
+		m.deleteRows()
+
+	case key.Matches(msg, m.keys.InsertCol):
+		m.insertCols()
+
+	case key.Matches(msg, m.keys.DeleteCol):
+		m.deleteCols()
+
+	case key.Matches(msg, m.keys.MoveRowUp):
+		m.moveRow(-1)
+
+	case key.Matches(msg, m.keys.MoveRowDown):
+		m.moveRow(1)
+
+	case key.Matches(msg, m.keys.DupRow):
+		m.duplicateRow()
+
+	case key.Matches(msg, m.keys.SortAsc):
+		m.sortRows([]sortKey{{col: m.cursorCol}})
+
+	case key.Matches(msg, m.keys.SortDesc):
+		m.sortRows([]sortKey{{col: m.cursorCol, desc: true}})
+
+	case key.Matches(msg, m.keys.SortBy):
+		return m.startSort()
+
+	case key.Matches(msg, m.keys.Filter):
+		return m.startFilter()
+
+	case key.Matches(msg, m.keys.Stats):
+		return m.startStats()
+
+	case key.Matches(msg, m.keys.Pivot):
+		return m.startPivot()
+
+	case key.Matches(msg, m.keys.Query):
+		return m.startQuery()
+
+	case key.Matches(msg, m.keys.Computed):
+		return m.startComputed()
+
+	case key.Matches(msg, m.keys.Duplicates):
+		return m.startDuplicates()
+
+	case key.Matches(msg, m.keys.Profile):
+		return m.startProfile()
+
+	case key.Matches(msg, m.keys.Outliers):
+		return m.startOutliers()
+
+	case key.Matches(msg, m.keys.Formats):
+		return m.startFormats()
+
+	case key.Matches(msg, m.keys.History):
+		m.mode = models.ModeHistory
+		m.historyCursor = 0
+		return m, nil
+
+	case key.Matches(msg, m.keys.CopyAs):
+		m.mode = models.ModeCopyAs
+		return m, nil
+
+	case key.Matches(msg, m.keys.Paste):
+		return m.startPaste()
+
+	case key.Matches(msg, m.keys.Replace):
+		return m.startReplace()
+
 	case key.Matches(msg, m.keys.Export):
 		m.mode = models.ModeExport
+		m.resetPromptHistory()
 		m.exportInput.Focus()
 		m.exportInput.SetValue("")
 		return m, textinput.Blink
@@ -220,20 +384,12 @@ func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case key.Matches(msg, m.keys.SelectRange):
-		if !m.isSelecting {
-			m.selectStart = [2]int{m.cursorRow, m.cursorCol}
-			m.selectEnd = [2]int{m.cursorRow, m.cursorCol}
-			m.isSelecting = true
-			m.status = models.StatusMsg{Message: "Selection started - Move cursor, press V to finish", Type: models.StatusInfo}
-		} else {
-			m.selectEnd = [2]int{m.cursorRow, m.cursorCol}
-			m.status = models.StatusMsg{
-				Message: fmt.Sprintf("Selected %dx%d range - Press v to visualize", 
-					abs(m.selectEnd[0]-m.selectStart[0])+1,
-					abs(m.selectEnd[1]-m.selectStart[1])+1),
-				Type: models.StatusSuccess,
-			}
-		}
+		m.selectStart = [2]int{m.cursorRow, m.cursorCol}
+		m.selectEnd = [2]int{m.cursorRow, m.cursorCol}
+		m.isSelecting = true
+		m.mode = models.ModeSelectRange
+		m.updateSelectionStats()
+		m.status = models.StatusMsg{Message: "Selection started - Move cursor, press V to finish", Type: models.StatusInfo}
 		return m, nil
 	}
 
@@ -246,17 +402,31 @@ func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	switch msg.Type {
 	case tea.KeyEscape:
+		// Drop live results and return to where the search started
 		m.mode = models.ModeNormal
 		m.searchInput.Blur()
+		if m.searchQuery == "" || m.runSearch(m.searchQuery) != nil {
+			m.clearSearch()
+		}
+		m.moveTo(m.searchOrigin)
 		return m, nil
 
+	case tea.KeyUp:
+		m.browsePromptHistory(&m.searchInput, "search", -1)
+		return m, m.scheduleLiveSearch()
+
+	case tea.KeyDown:
+		m.browsePromptHistory(&m.searchInput, "search", 1)
+		return m, m.scheduleLiveSearch()
+
 	case tea.KeyEnter:
 		term := strings.TrimSpace(m.searchInput.Value())
 		if term != "" {
-			m.searchQuery = term
-			sheet := m.sheets[m.currentSheet]
-			m.searchResults = loader.SearchSheet(sheet, term)
-			m.searchIndex = 0
+			m.recordPrompt("search", term)
+			if err := m.runSearch(term); err != nil {
+				m.status = models.StatusMsg{Message: err.Error(), Type: models.StatusError}
+				return m, nil
+			}
 			if len(m.searchResults) > 0 {
 				m.jumpToSearchResult()
 				m.status = models.StatusMsg{
@@ -272,7 +442,20 @@ func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	if msg.String() == "alt+a" {
+		m.searchAll = !m.searchAll
+		return m, m.scheduleLiveSearch()
+	}
+	if toggleSearchOption(&m.searchOpts, msg.String()) {
+		return m, m.scheduleLiveSearch()
+	}
+
+	before := m.searchInput.Value()
 	m.searchInput, cmd = m.searchInput.Update(msg)
+	if m.searchInput.Value() != before {
+		m.resetPromptHistory()
+		cmd = tea.Batch(cmd, m.scheduleLiveSearch())
+	}
 	return m, cmd
 }
 
@@ -297,14 +480,27 @@ func (m Model) updateJump(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case tea.KeyEnter:
 		input := strings.TrimSpace(m.jumpInput.Value())
 		if input != "" {
+			m.recordPrompt("jump", input)
 			m.jumpToCell(input)
 		}
 		m.mode = models.ModeNormal
 		m.jumpInput.Blur()
 		return m, nil
+
+	case tea.KeyUp:
+		m.browsePromptHistory(&m.jumpInput, "jump", -1)
+		return m, nil
+
+	case tea.KeyDown:
+		m.browsePromptHistory(&m.jumpInput, "jump", 1)
+		return m, nil
 	}
 
+	before := m.jumpInput.Value()
 	m.jumpInput, cmd = m.jumpInput.Update(msg)
+	if m.jumpInput.Value() != before {
+		m.resetPromptHistory()
+	}
 	return m, cmd
 }
 
@@ -321,14 +517,27 @@ func (m Model) updateExport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case tea.KeyEnter:
 		filename := strings.TrimSpace(m.exportInput.Value())
 		if filename != "" {
+			m.recordPrompt("export", filename)
 			m.exportSheet(filename)
 		}
 		m.mode = models.ModeNormal
 		m.exportInput.Blur()
 		return m, nil
+
+	case tea.KeyUp:
+		m.browsePromptHistory(&m.exportInput, "export", -1)
+		return m, nil
+
+	case tea.KeyDown:
+		m.browsePromptHistory(&m.exportInput, "export", 1)
+		return m, nil
 	}
 
+	before := m.exportInput.Value()
 	m.exportInput, cmd = m.exportInput.Update(msg)
+	if m.exportInput.Value() != before {
+		m.resetPromptHistory()
+	}
 	return m, cmd
 }
 
@@ -360,83 +569,13 @@ func (m *Model) jumpToSearchResult() {
 	}
 
 	result := m.searchResults[m.searchIndex]
-	m.cursorRow = result.Row
-	m.cursorCol = result.Col
-	m.centerView()
-}
-
-// jumpToCell jumps to a specific cell based on user input
-func (m *Model) jumpToCell(input string) {
-	sheet := m.sheets[m.currentSheet]
-	input = strings.ToUpper(strings.TrimSpace(input))
-
-	// Format: "A100" (column letter + row number)
-	if len(input) > 0 && input[0] >= 'A' && input[0] <= 'Z' {
-		col := 0
-		row := 0
-		i := 0
-
-		// Parse column letters
-		for i < len(input) && input[i] >= 'A' && input[i] <= 'Z' {
-			col = col*26 + int(input[i]-'A') + 1
-			i++
-		}
-		col-- // Convert to 0-indexed
-
-		// Parse row number
-		if i < len(input) {
-			if r, err := strconv.Atoi(input[i:]); err == nil {
-				row = r - 1 // Convert to 0-indexed
-			}
-		}
-
-		if row >= 0 && row < sheet.MaxRows && col >= 0 && col < sheet.MaxCols {
-			m.cursorRow = row
-			m.cursorCol = col
-			m.centerView()
-			m.status = models.StatusMsg{
-				Message: fmt.Sprintf("→ %s", ui.ColIndexToLetter(col)+fmt.Sprintf("%d", row+1)),
-				Type:    models.StatusSuccess,
-			}
-			return
-		}
-	}
+	m.moveTo(position{sheet: result.sheet, row: result.cell.Row, col: result.cell.Col})
 
-	// Format: "500" (row number only)
-	if row, err := strconv.Atoi(input); err == nil {
-		row-- // Convert to 0-indexed
-		if row >= 0 && row < sheet.MaxRows {
-			m.cursorRow = row
-			m.centerView()
-			m.status = models.StatusMsg{
-				Message: fmt.Sprintf("→ Row %d", row+1),
-				Type:    models.StatusSuccess,
-			}
-			return
-		}
+	message := fmt.Sprintf("Match %d/%d", m.searchIndex+1, len(m.searchResults))
+	if m.searchAll {
+		message += " • " + m.sheets[result.sheet].Name
 	}
-
-	// Format: "10,5" (row,col)
-	if parts := strings.Split(input, ","); len(parts) == 2 {
-		if row, err1 := strconv.Atoi(strings.TrimSpace(parts[0])); err1 == nil {
-			if col, err2 := strconv.Atoi(strings.TrimSpace(parts[1])); err2 == nil {
-				row-- // Convert to 0-indexed
-				col-- // Convert to 0-indexed
-				if row >= 0 && row < sheet.MaxRows && col >= 0 && col < sheet.MaxCols {
-					m.cursorRow = row
-					m.cursorCol = col
-					m.centerView()
-					m.status = models.StatusMsg{
-						Message: fmt.Sprintf("→ %d,%d", row+1, col+1),
-						Type:    models.StatusSuccess,
-					}
-					return
-				}
-			}
-		}
-	}
-
-	m.status = models.StatusMsg{Message: "Invalid cell reference", Type: models.StatusError}
+	m.status = models.StatusMsg{Message: message, Type: models.StatusInfo}
 }
 
 // copyCell copies the current cell to clipboard
@@ -444,15 +583,13 @@ func (m *Model) copyCell() {
 	sheet := m.sheets[m.currentSheet]
 	if m.cursorRow < len(sheet.Rows) && m.cursorCol < len(sheet.Rows[m.cursorRow]) {
 		cell := sheet.Rows[m.cursorRow][m.cursorCol]
-		value := cell.Value
-		if m.showFormulas && cell.Formula != "" {
-			value = "=" + cell.Formula
-		}
-		if err := clipboard.WriteAll(value); err != nil {
-			m.status = models.StatusMsg{Message: "Failed to copy", Type: models.StatusError}
+		cell.Value = m.cellValues(m.currentSheet)(m.cursorRow, m.cursorCol)
+		value := m.cellText(cell)
+		if backend, err := clipboard.WriteAll(value); err != nil {
+			m.status = models.StatusMsg{Message: "Failed to copy: " + err.Error(), Type: models.StatusError}
 		} else {
 			m.status = models.StatusMsg{
-				Message: fmt.Sprintf("Copied: %s", ui.Truncate(value, 30)),
+				Message: fmt.Sprintf("Copied: %s%s", ui.Truncate(value, 30), clipboardNote(backend)),
 				Type:    models.StatusSuccess,
 			}
 		}
@@ -464,25 +601,35 @@ func (m *Model) copyRow() {
 	sheet := m.sheets[m.currentSheet]
 	if m.cursorRow < len(sheet.Rows) {
 		row := sheet.Rows[m.cursorRow]
+		value := m.cellValues(m.currentSheet)
 		values := make([]string, 0, len(row))
 		for _, cell := range row {
-			values = append(values, cell.Value)
+			cell.Value = value(cell.Row, cell.Col)
+			values = append(values, m.cellText(cell))
 		}
 		rowText := strings.Join(values, "\t")
-		if err := clipboard.WriteAll(rowText); err != nil {
-			m.status = models.StatusMsg{Message: "Failed to copy row", Type: models.StatusError}
+		if backend, err := clipboard.WriteAll(rowText); err != nil {
+			m.status = models.StatusMsg{Message: "Failed to copy row: " + err.Error(), Type: models.StatusError}
 		} else {
 			m.status = models.StatusMsg{
-				Message: fmt.Sprintf("Copied row %d (%d cells)", m.cursorRow+1, len(values)),
+				Message: fmt.Sprintf("Copied row %d (%d cells)%s", m.cursorRow+1, len(values), clipboardNote(backend)),
 				Type:    models.StatusSuccess,
 			}
 		}
 	}
 }
 
+// clipboardNote describes a non-default clipboard backend for status messages
+func clipboardNote(backend clipboard.Backend) string {
+	if backend == clipboard.BackendOSC52 {
+		return " (OSC 52)"
+	}
+	return ""
+}
+
 // exportSheet exports the current sheet to a file
 func (m *Model) exportSheet(filename string) {
-	sheet := m.sheets[m.currentSheet]
+	sheet := m.materialize(m.currentSheet)
 	var err error
 
 	if strings.HasSuffix(strings.ToLower(filename), ".csv") {
@@ -533,14 +680,14 @@ func (m Model) updateSelectRange(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	switch {
 	case key.Matches(msg, m.keys.Up):
-		if m.cursorRow > 0 {
-			m.cursorRow--
+		if row := m.stepRows(m.cursorRow, -1); row != m.cursorRow {
+			m.cursorRow = row
 			m.selectEnd = [2]int{m.cursorRow, m.cursorCol}
 			m.adjustViewport()
 		}
 	case key.Matches(msg, m.keys.Down):
-		if m.cursorRow < sheet.MaxRows-1 {
-			m.cursorRow++
+		if row := m.stepRows(m.cursorRow, 1); row != m.cursorRow {
+			m.cursorRow = row
 			m.selectEnd = [2]int{m.cursorRow, m.cursorCol}
 			m.adjustViewport()
 		}
@@ -559,8 +706,10 @@ func (m Model) updateSelectRange(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keys.SelectRange):
 		m.mode = models.ModeNormal
 		m.status = models.StatusMsg{
-			Message: "Selected range - Press v to visualize",
-			Type:    models.StatusSuccess,
+			Message: fmt.Sprintf("Selected %dx%d range - Press v to visualize",
+				abs(m.selectEnd[0]-m.selectStart[0])+1,
+				abs(m.selectEnd[1]-m.selectStart[1])+1),
+			Type: models.StatusSuccess,
 		}
 	case msg.Type == tea.KeyEscape:
 		m.isSelecting = false
@@ -568,6 +717,7 @@ func (m Model) updateSelectRange(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.status = models.StatusMsg{Message: "Selection cancelled", Type: models.StatusInfo}
 	}
 
+	m.updateSelectionStats()
 	return m, nil
 }
 
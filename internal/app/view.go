@@ -3,6 +3,7 @@ package app
 import (
 	"fmt"
 	"math"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -36,6 +37,36 @@ func (m Model) View() string {
 		return ui.RenderModal(m.width, m.height, m.renderChart())
 	case models.ModeSelectRange:
 		return m.renderSelectRange()
+	case models.ModeConfirmQuit:
+		return ui.RenderModal(m.width, m.height, m.renderConfirmQuit())
+	case models.ModeHistory:
+		return ui.RenderModal(m.width, m.height, m.renderHistory())
+	case models.ModePaste:
+		return ui.RenderModal(m.width, m.height, m.renderPaste())
+	case models.ModeCopyAs:
+		return ui.RenderModal(m.width, m.height, m.renderCopyAs())
+	case models.ModeReplace:
+		return ui.RenderModal(m.width, m.height, m.renderReplace())
+	case models.ModeSearchResults:
+		return ui.RenderModal(m.width, m.height, m.renderSearchResults())
+	case models.ModeFinder:
+		return ui.RenderModal(m.width, m.height, m.renderFinder())
+	case models.ModeSort:
+		return ui.RenderModal(m.width, m.height, m.renderSort())
+	case models.ModeFilter:
+		return ui.RenderModal(m.width, m.height, m.renderFilter())
+	case models.ModeStats:
+		return ui.RenderModal(m.width, m.height, m.renderStats())
+	case models.ModePivot:
+		return ui.RenderModal(m.width, m.height, m.renderPivot())
+	case models.ModeDuplicates:
+		return ui.RenderModal(m.width, m.height, m.renderDuplicates())
+	case models.ModeProfile:
+		return ui.RenderModal(m.width, m.height, m.renderProfile())
+	case models.ModeOutliers:
+		return ui.RenderModal(m.width, m.height, m.renderOutliers())
+	case models.ModeFormats:
+		return ui.RenderModal(m.width, m.height, m.renderFormats())
 	default:
 		return m.renderNormal()
 	}
@@ -66,11 +97,22 @@ func (m Model) renderNormal() string {
 	} else {
 		title += fmt.Sprintf(" • %s", sheet.Name)
 	}
+	if sheet.Source != "" {
+		title += " • from " + filepath.Base(sheet.Source)
+	} else if sheet.Virtual {
+		title += " • generated"
+	} else if sheet.Dirty {
+		title += " ● modified"
+	}
 	b.WriteString(m.styles.Title.Render(title))
 	b.WriteString("\n")
 
 	// Formula bar
-	b.WriteString(m.renderFormulaBar())
+	if m.mode == models.ModeEdit {
+		b.WriteString(m.renderEditBar())
+	} else {
+		b.WriteString(m.renderFormulaBar())
+	}
 	b.WriteString("\n\n")
 
 	// Render table
@@ -80,8 +122,14 @@ func (m Model) renderNormal() string {
 	b.WriteString("\n")
 	b.WriteString(m.renderStatusBar())
 
-	// Search bar (vim-style at bottom)
-	if m.mode == models.ModeSearch || m.searchQuery != "" {
+	// Search or query bar (vim-style at bottom)
+	if m.mode == models.ModeQuery {
+		b.WriteString("\n")
+		b.WriteString(m.renderQueryBar())
+	} else if m.mode == models.ModeComputed {
+		b.WriteString("\n")
+		b.WriteString(m.renderComputedBar())
+	} else if m.mode == models.ModeSearch || m.searchQuery != "" {
 		b.WriteString("\n")
 		b.WriteString(m.renderSearchBar())
 	}
@@ -106,7 +154,19 @@ func (m Model) renderFormulaBar() string {
 			Bold(true).
 			Render(cellRef)
 
-		if cell.Formula != "" {
+		if cc := sheet.ComputedCell(m.cursorRow, m.cursorCol); cc != nil {
+			value, err := m.newComputer(m.currentSheet).value(m.cursorRow, m.cursorCol)
+			if err != nil {
+				value = err.Error()
+			}
+			formulaText += lipgloss.NewStyle().
+				Foreground(t.Accent).
+				Italic(true).
+				Render(" ƒ "+ui.Truncate(cc.Expr, 60)) +
+				lipgloss.NewStyle().
+					Foreground(t.Text).
+					Render(" → "+ui.Truncate(value, 60))
+		} else if cell.Formula != "" {
 			formulaText += lipgloss.NewStyle().
 				Foreground(t.Text).
 				Render(" = " + ui.Truncate(cell.Formula, 100))
@@ -135,8 +195,17 @@ func (m Model) renderTable() string {
 
 	for col := m.offsetCol; col < ui.Min(m.offsetCol+visibleCols, sheet.MaxCols); col++ {
 		colLetter := ui.ColIndexToLetter(col)
+		if sheet.Filter != nil && sheet.Filter.Column(col) != nil {
+			colLetter += " ▾"
+		}
+		computed := sheet.ComputedAt(col) != nil
+		if computed {
+			colLetter += " ƒ"
+		}
 		if col == m.cursorCol {
-			b.WriteString(m.styles.HeaderHighlight.Render(ui.PadCenter(colLetter, ui.MinCellWidth)))
+			b.WriteString(m.styles.HeaderHighlight.Copy().Italic(computed).Render(ui.PadCenter(colLetter, ui.MinCellWidth)))
+		} else if computed {
+			b.WriteString(m.styles.ComputedHeader.Render(ui.PadCenter(colLetter, ui.MinCellWidth)))
 		} else {
 			b.WriteString(m.styles.Header.Render(ui.PadCenter(colLetter, ui.MinCellWidth)))
 		}
@@ -144,9 +213,16 @@ func (m Model) renderTable() string {
 	}
 	b.WriteString("\n")
 
-	// Data rows
-	endRow := ui.Min(m.offsetRow+visibleRows, sheet.MaxRows)
-	for row := m.offsetRow; row < endRow; row++ {
+	// Data rows, skipping those hidden by the autofilter. Computed columns
+	// are only evaluated for the rows drawn.
+	comp := m.newComputer(m.currentSheet)
+	shown := 0
+	for row := m.offsetRow; row < sheet.MaxRows && shown < visibleRows; row++ {
+		if m.rowHidden(row) {
+			continue
+		}
+		shown++
+
 		// Row number
 		if row == m.cursorRow {
 			b.WriteString(m.styles.SelectedRowNum.Render(fmt.Sprintf("%d", row+1)))
@@ -160,7 +236,9 @@ func (m Model) renderTable() string {
 			for col := m.offsetCol; col < ui.Min(m.offsetCol+visibleCols, sheet.MaxCols); col++ {
 				cellText := ""
 
-				if col < len(sheet.Rows[row]) {
+				if comp != nil && sheet.ComputedAt(col) != nil {
+					cellText = comp.text(row, col)
+				} else if col < len(sheet.Rows[row]) {
 					cell := sheet.Rows[row][col]
 					if m.showFormulas && cell.Formula != "" {
 						cellText = "=" + cell.Formula
@@ -182,7 +260,23 @@ func (m Model) renderTable() string {
 						Background(theme.GetCurrentTheme().Secondary).
 						Width(ui.MinCellWidth)
 				} else if m.isSearchMatch(row, col) {
-					style = m.styles.SearchMatch
+					b.WriteString(m.renderSearchCell(cellText))
+					b.WriteString(sep)
+					continue
+				} else if m.isOutlier(row, col) {
+					style = m.styles.Outlier
+					if row == m.cursorRow {
+						style = style.Copy().Background(theme.GetCurrentTheme().RowHighlight)
+					}
+				} else if m.isDuplicateRow(row) {
+					style = m.styles.Duplicate
+					if row == m.cursorRow {
+						style = style.Copy().Background(theme.GetCurrentTheme().RowHighlight)
+					}
+				} else if st, ok := m.formatter.At(row, col); ok {
+					b.WriteString(m.renderFormatCell(cellText, st, row == m.cursorRow))
+					b.WriteString(sep)
+					continue
 				} else if row == m.cursorRow {
 					style = m.styles.RowHighlight
 				} else if col == m.cursorCol {
@@ -235,6 +329,31 @@ func (m Model) renderStatusBar() string {
 		parts = append(parts, lipgloss.NewStyle().Foreground(t.Accent).Render("Formulas"))
 	}
 
+	if f := sheet.Filter; f != nil {
+		parts = append(parts, lipgloss.NewStyle().
+			Foreground(t.Accent).
+			Bold(true).
+			Render(fmt.Sprintf("▾ %d filter(s) • %d/%d rows", len(f.Columns), f.Shown, sheet.MaxRows-f.HeaderRow-1)))
+	}
+
+	if m.isSelecting {
+		a := m.selectAgg
+		stat := func(label, value string) string {
+			return lipgloss.NewStyle().Foreground(t.Secondary).Bold(true).Render(label) +
+				lipgloss.NewStyle().Foreground(t.Text).Render(" "+value)
+		}
+		sel := []string{stat("Count:", fmt.Sprint(a.Count))}
+		if a.Numeric > 0 {
+			sel = append(sel,
+				stat("Num:", fmt.Sprint(a.Numeric)),
+				stat("Sum:", formatStat(a.Sum)),
+				stat("Avg:", formatStat(a.Mean())),
+				stat("Min:", formatStat(a.Min)),
+				stat("Max:", formatStat(a.Max)))
+		}
+		parts = append(parts, strings.Join(sel, "  "))
+	}
+
 	if len(m.searchResults) > 0 {
 		parts = append(parts, lipgloss.NewStyle().
 			Foreground(t.SearchMatch).
@@ -242,6 +361,13 @@ func (m Model) renderStatusBar() string {
 			Render(fmt.Sprintf("🔍 %d/%d", m.searchIndex+1, len(m.searchResults))))
 	}
 
+	if len(m.outlierCells) > 0 {
+		parts = append(parts, lipgloss.NewStyle().
+			Foreground(t.Warning).
+			Bold(true).
+			Render(fmt.Sprintf("⚠ %d", len(m.outlierCells))))
+	}
+
 	if m.status.Message != "" {
 		statusColor := ui.GetStatusColor(m.status.Type)
 		parts = append(parts, lipgloss.NewStyle().
@@ -259,10 +385,16 @@ func (m Model) renderSearchBar() string {
 	if m.mode == models.ModeSearch {
 		prompt := m.styles.SearchPrompt.Render("/")
 		input := m.searchInput.View()
-		return m.styles.SearchBar.Render(prompt + input)
+		flags := lipgloss.NewStyle().
+			Foreground(t.DimText).
+			Render("  " + searchFlags(m.searchOpts, m.searchAll) + "  (alt+r regex, alt+c case, alt+w word, alt+e cell, alt+f formulas, alt+a all sheets)")
+		return m.styles.SearchBar.Render(prompt + input + flags)
 	} else if m.searchQuery != "" {
 		searchInfo := m.styles.SearchPrompt.Render("/") +
 			lipgloss.NewStyle().Foreground(t.Text).Render(m.searchQuery)
+		if flags := searchFlags(m.searchOpts, m.searchAll); flags != "" {
+			searchInfo += lipgloss.NewStyle().Foreground(t.Accent).Render("  " + flags)
+		}
 		if len(m.searchResults) > 0 {
 			searchInfo += lipgloss.NewStyle().
 				Foreground(t.DimText).
@@ -309,11 +441,17 @@ func (m Model) renderJump() string {
 	content += m.styles.ModalKey.Render("Enter cell reference:") + "\n"
 	content += m.jumpInput.View() + "\n\n"
 	content += lipgloss.NewStyle().Foreground(t.DimText).Render("Formats:\n")
-	content += lipgloss.NewStyle().Foreground(t.Text).Render("  • A100   (column + row)\n")
-	content += lipgloss.NewStyle().Foreground(t.Text).Render("  • 500    (row only)\n")
-	content += lipgloss.NewStyle().Foreground(t.Text).Render("  • 10,5   (row,col)")
-
-	return m.styles.Modal.Width(50).Render(content)
+	content += lipgloss.NewStyle().Foreground(t.Text).Render("  • A100             (column + row)\n")
+	content += lipgloss.NewStyle().Foreground(t.Text).Render("  • Sheet2!B7        (another sheet)\n")
+	content += lipgloss.NewStyle().Foreground(t.Text).Render("  • 'My Sheet'!A1:C20 (select range)\n")
+	content += lipgloss.NewStyle().Foreground(t.Text).Render("  • R1C1             (row/col numbers)\n")
+	content += lipgloss.NewStyle().Foreground(t.Text).Render("  • +50, -10         (relative rows)\n")
+	content += lipgloss.NewStyle().Foreground(t.Text).Render("  • D                (column only)\n")
+	content += lipgloss.NewStyle().Foreground(t.Text).Render("  • 500              (row only)\n")
+	content += lipgloss.NewStyle().Foreground(t.Text).Render("  • 10,5             (row,col)\n\n")
+	content += lipgloss.NewStyle().Foreground(t.DimText).Render("[ / ] to go back/forward after jumping")
+
+	return m.styles.Modal.Width(54).Render(content)
 }
 
 // renderExport renders the export modal
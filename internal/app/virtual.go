@@ -0,0 +1,22 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/vex/pkg/models"
+)
+
+// addVirtualSheet appends a generated sheet named after base, numbered to
+// keep names unique, and switches to it
+func (m *Model) addVirtualSheet(base string, values [][]string) {
+	name := base
+	for n := 2; m.findSheet(name) >= 0; n++ {
+		name = fmt.Sprintf("%s %d", base, n)
+	}
+
+	sheet := models.NewSheet(name, values)
+	sheet.Virtual = true
+	m.sheets = append(m.sheets, sheet)
+	m.pushJumpHistory()
+	m.moveTo(position{sheet: len(m.sheets) - 1, row: 0, col: 0})
+}
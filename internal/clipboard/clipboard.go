@@ -0,0 +1,104 @@
+// Package clipboard copies text to the system clipboard, falling back to
+// OSC 52 terminal escape sequences where no clipboard tool is available.
+package clipboard
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	sysclip "github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// Backend selects how text reaches the clipboard
+type Backend string
+
+const (
+	// BackendAuto uses OSC 52 over SSH and the system clipboard otherwise,
+	// falling back to OSC 52 when the system clipboard fails
+	BackendAuto Backend = "auto"
+	// BackendSystem only uses the system clipboard (xclip, pbcopy, ...)
+	BackendSystem Backend = "system"
+	// BackendOSC52 only uses OSC 52 escape sequences
+	BackendOSC52 Backend = "osc52"
+)
+
+var (
+	backend = BackendAuto
+	tmux    = os.Getenv("TMUX") != ""
+	screen  = strings.HasPrefix(os.Getenv("TERM"), "screen") && os.Getenv("TMUX") == ""
+
+	// output receives OSC 52 sequences; stderr is the terminal but is not
+	// used by the renderer, so sequences do not interleave with frames
+	output io.Writer = os.Stderr
+)
+
+// Configure sets the clipboard backend and whether OSC 52 sequences are
+// wrapped for tmux passthrough in addition to $TMUX detection
+func Configure(name string, tmuxPassthrough bool) error {
+	switch b := Backend(strings.ToLower(name)); b {
+	case "", BackendAuto:
+		backend = BackendAuto
+	case BackendSystem, BackendOSC52:
+		backend = b
+	default:
+		return fmt.Errorf("unknown clipboard backend %q (use auto, system or osc52)", name)
+	}
+	if tmuxPassthrough {
+		tmux = true
+		screen = false
+	}
+	return nil
+}
+
+// WriteAll copies text to the clipboard and returns the backend used
+func WriteAll(text string) (Backend, error) {
+	switch backend {
+	case BackendSystem:
+		return BackendSystem, sysclip.WriteAll(text)
+	case BackendOSC52:
+		return BackendOSC52, writeOSC52(text)
+	}
+
+	if !isSSH() {
+		if err := sysclip.WriteAll(text); err == nil {
+			return BackendSystem, nil
+		}
+	}
+	return BackendOSC52, writeOSC52(text)
+}
+
+// ReadAll returns the system clipboard contents. OSC 52 reads are not
+// supported by most terminals, so only the system clipboard is used.
+func ReadAll() (string, error) {
+	if backend == BackendOSC52 {
+		return "", fmt.Errorf("reading the clipboard is not supported with the osc52 backend")
+	}
+	text, err := sysclip.ReadAll()
+	if err != nil {
+		return "", fmt.Errorf("failed to read clipboard: %w", err)
+	}
+	return text, nil
+}
+
+// writeOSC52 asks the terminal to set its clipboard
+func writeOSC52(text string) error {
+	seq := osc52.New(text)
+	if tmux {
+		seq = seq.Tmux()
+	} else if screen {
+		seq = seq.Screen()
+	}
+	if _, err := seq.WriteTo(output); err != nil {
+		return fmt.Errorf("failed to write OSC 52 sequence: %w", err)
+	}
+	return nil
+}
+
+// isSSH reports whether vex runs in an SSH session, where the system
+// clipboard belongs to the remote host rather than the user's terminal
+func isSSH() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
@@ -0,0 +1,268 @@
+// Package condfmt evaluates the conditional format rules of a sheet.
+package condfmt
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vex/internal/loader"
+	"github.com/vex/internal/stats"
+	"github.com/vex/pkg/models"
+)
+
+// Style is how the rules show a cell. Colours are "#RRGGBB" or theme
+// colour names; empty leaves the colour as it is.
+type Style struct {
+	Fg, Bg   string
+	Bar      float64 // Share of the cell a data bar covers, 0 for none
+	BarColor string
+}
+
+// Formatter evaluates the rules of one sheet, working out the bounds of
+// colour scales, data bars and top/bottom rules on first use
+type Formatter struct {
+	sheet  *models.Sheet
+	value  func(row, col int) string
+	bounds []*bounds
+}
+
+// bounds are the values a rule compares cells with
+type bounds struct {
+	stops     []float64 // Colour scale and data bar stops, lowest first
+	threshold float64   // Top/bottom rules: the last value still included
+	ok        bool      // The range holds numbers
+}
+
+// New returns the formatter of a sheet whose cell text value returns, or
+// nil when the sheet has no rules
+func New(sheet *models.Sheet, value func(row, col int) string) *Formatter {
+	if len(sheet.Formats) == 0 {
+		return nil
+	}
+	return &Formatter{sheet: sheet, value: value, bounds: make([]*bounds, len(sheet.Formats))}
+}
+
+// At returns the style the rules give a cell. Rules earlier in the list
+// win where several set the same colour. A nil formatter styles nothing.
+func (f *Formatter) At(row, col int) (Style, bool) {
+	var st Style
+	if f == nil {
+		return st, false
+	}
+	found := false
+	for i := range f.sheet.Formats {
+		r := &f.sheet.Formats[i]
+		if !inRanges(r.Ranges, row, col) {
+			continue
+		}
+		v := strings.TrimSpace(f.value(row, col))
+
+		switch r.Kind {
+		case models.FormatCell:
+			if matchCell(r, v) {
+				paint(&st, r.Fg, r.Fill)
+				found = true
+			}
+
+		case models.FormatTopBottom:
+			n, ok := loader.ParseNumber(v)
+			b := f.boundsOf(i)
+			if ok && b.ok && ((!r.Bottom && n >= b.threshold) || (r.Bottom && n <= b.threshold)) {
+				paint(&st, r.Fg, r.Fill)
+				found = true
+			}
+
+		case models.FormatColorScale:
+			n, ok := loader.ParseNumber(v)
+			b := f.boundsOf(i)
+			if ok && b.ok && st.Bg == "" {
+				st.Bg = scaleColor(r.Stops, b.stops, n)
+				if st.Fg == "" {
+					st.Fg = Contrast(st.Bg)
+				}
+				found = true
+			}
+
+		case models.FormatDataBar:
+			n, ok := loader.ParseNumber(v)
+			b := f.boundsOf(i)
+			if ok && b.ok && st.Bar == 0 {
+				lo, hi := b.stops[0], b.stops[len(b.stops)-1]
+				st.Bar = 1
+				if hi > lo {
+					st.Bar = math.Max(0, math.Min(1, (n-lo)/(hi-lo)))
+				}
+				// Like Excel, the smallest value still shows a sliver of bar
+				st.Bar = math.Max(st.Bar, 0.1)
+				st.BarColor = r.Fill
+				found = true
+			}
+		}
+	}
+	return st, found
+}
+
+// paint sets the colours of a style that no earlier rule has set
+func paint(st *Style, fg, bg string) {
+	if st.Fg == "" {
+		st.Fg = fg
+	}
+	if st.Bg == "" {
+		st.Bg = bg
+	}
+}
+
+// inRanges reports whether any of the ranges contains a cell
+func inRanges(ranges []models.CellRange, row, col int) bool {
+	for _, r := range ranges {
+		if r.Contains(row, col) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchCell reports whether a value satisfies a cell rule
+func matchCell(r *models.FormatRule, v string) bool {
+	switch r.Op {
+	case "between", "notBetween":
+		n, ok := loader.ParseNumber(v)
+		lo, ok1 := loader.ParseNumber(r.Value)
+		hi, ok2 := loader.ParseNumber(r.Value2)
+		if !ok || !ok1 || !ok2 {
+			return false
+		}
+		in := n >= math.Min(lo, hi) && n <= math.Max(lo, hi)
+		return in == (r.Op == "between")
+	}
+	return loader.MatchColumnFilter(models.ColumnFilter{Op: r.Op, Operand: r.Value}, v)
+}
+
+// boundsOf returns the bounds of a rule, working them out from the
+// numbers in its ranges on first use
+func (f *Formatter) boundsOf(i int) *bounds {
+	if f.bounds[i] != nil {
+		return f.bounds[i]
+	}
+	r := &f.sheet.Formats[i]
+	var nums []float64
+	for _, rg := range r.Ranges {
+		last := rg.LastRow
+		if last < 0 || last >= f.sheet.MaxRows {
+			last = f.sheet.MaxRows - 1
+		}
+		for row := rg.FirstRow; row <= last; row++ {
+			for col := rg.FirstCol; col <= rg.LastCol; col++ {
+				if n, ok := loader.ParseNumber(f.value(row, col)); ok {
+					nums = append(nums, n)
+				}
+			}
+		}
+	}
+	sort.Float64s(nums)
+
+	b := &bounds{ok: len(nums) > 0}
+	f.bounds[i] = b
+	if !b.ok {
+		return b
+	}
+	switch r.Kind {
+	case models.FormatTopBottom:
+		n := r.Rank
+		if r.Percent {
+			n = int(math.Ceil(float64(len(nums)) * float64(r.Rank) / 100))
+		}
+		n = max(1, min(n, len(nums)))
+		b.threshold = nums[n-1]
+		if !r.Bottom {
+			b.threshold = nums[len(nums)-n]
+		}
+	default:
+		for j, stop := range r.Stops {
+			b.stops = append(b.stops, stopValue(stop, nums, j == 0, j == len(r.Stops)-1))
+		}
+	}
+	return b
+}
+
+// stopValue resolves a colour scale or data bar stop against the sorted
+// numbers of its range. Stops of unknown type fall back to the lowest,
+// middle or highest value.
+func stopValue(stop models.FormatStop, nums []float64, first, last bool) float64 {
+	lo, hi := nums[0], nums[len(nums)-1]
+	v, err := strconv.ParseFloat(stop.Value, 64)
+	known := err == nil
+	switch stop.Type {
+	case "min":
+		return lo
+	case "max":
+		return hi
+	case "num":
+		if known {
+			return v
+		}
+	case "percent":
+		if known {
+			return lo + (hi-lo)*v/100
+		}
+	case "percentile":
+		if known {
+			return stats.Percentile(nums, math.Max(0, math.Min(100, v)))
+		}
+	}
+	switch {
+	case first:
+		return lo
+	case last:
+		return hi
+	}
+	return stats.Percentile(nums, 50)
+}
+
+// scaleColor interpolates the colour of a value between the stops of a
+// colour scale
+func scaleColor(stops []models.FormatStop, at []float64, n float64) string {
+	if n <= at[0] {
+		return stops[0].Color
+	}
+	for j := 1; j < len(at); j++ {
+		if n <= at[j] {
+			t := 1.0
+			if at[j] > at[j-1] {
+				t = (n - at[j-1]) / (at[j] - at[j-1])
+			}
+			return mix(stops[j-1].Color, stops[j].Color, t)
+		}
+	}
+	return stops[len(stops)-1].Color
+}
+
+// mix blends two #RRGGBB colours, t of the way from a to b
+func mix(a, b string, t float64) string {
+	ar, ag, ab := rgb(a)
+	br, bg, bb := rgb(b)
+	lerp := func(x, y float64) uint8 { return uint8(math.Round(x + (y-x)*t)) }
+	return fmt.Sprintf("#%02X%02X%02X", lerp(ar, br), lerp(ag, bg), lerp(ab, bb))
+}
+
+// Contrast returns black or white, whichever reads better on a #RRGGBB
+// background
+func Contrast(bg string) string {
+	r, g, b := rgb(bg)
+	if 0.299*r+0.587*g+0.114*b > 150 {
+		return "#000000"
+	}
+	return "#FFFFFF"
+}
+
+// rgb splits a #RRGGBB colour, treating anything else as black
+func rgb(c string) (r, g, b float64) {
+	n, err := strconv.ParseUint(strings.TrimPrefix(c, "#"), 16, 32)
+	if err != nil || len(c) != 7 {
+		return 0, 0, 0
+	}
+	return float64(n >> 16 & 0xFF), float64(n >> 8 & 0xFF), float64(n & 0xFF)
+}
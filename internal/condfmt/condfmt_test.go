@@ -0,0 +1,94 @@
+package condfmt
+
+import (
+	"testing"
+
+	"github.com/vex/pkg/models"
+)
+
+func TestAt(t *testing.T) {
+	sheet := models.NewSheet("S", [][]string{
+		{"Name", "Score", "Bar"},
+		{"a", "10", "0"},
+		{"b", "20", "50"},
+		{"c", "30", "100"},
+		{"d", "x", ""},
+	})
+	column := func(col int) []models.CellRange {
+		return []models.CellRange{{FirstRow: 1, FirstCol: col, LastRow: -1, LastCol: col}}
+	}
+	sheet.Formats = []models.FormatRule{
+		{Kind: models.FormatCell, Ranges: column(1), Op: ">", Value: "15", Fg: "error"},
+		{Kind: models.FormatCell, Ranges: column(1), Op: ">=", Value: "30", Fg: "success", Fill: "warning"},
+		{Kind: models.FormatTopBottom, Ranges: column(0), Rank: 1, Fg: "accent"},
+		{Kind: models.FormatColorScale, Ranges: column(1), Stops: []models.FormatStop{
+			{Type: "min", Color: "#000000"}, {Type: "max", Color: "#FFFFFF"},
+		}},
+		{Kind: models.FormatDataBar, Ranges: column(2), Fill: "#638EC6", Stops: []models.FormatStop{
+			{Type: "min"}, {Type: "max"},
+		}},
+	}
+	f := New(&sheet, func(row, col int) string { return sheet.CellAt(row, col).Value })
+
+	tests := []struct {
+		name     string
+		row, col int
+		want     Style
+		found    bool
+	}{
+		{"header is outside the ranges", 0, 1, Style{}, false},
+		{"scale only", 1, 1, Style{Fg: "#FFFFFF", Bg: "#000000"}, true},
+		{"first matching rule wins the colour", 2, 1, Style{Fg: "error", Bg: "#808080"}, true},
+		{"later rule fills what earlier ones left", 3, 1, Style{Fg: "error", Bg: "warning"}, true},
+		{"text compares above numbers, as in Excel", 4, 1, Style{Fg: "error", Bg: "warning"}, true},
+		{"top rule ignores text", 1, 0, Style{}, false},
+		{"smallest bar keeps a sliver", 1, 2, Style{Bar: 0.1, BarColor: "#638EC6"}, true},
+		{"middle bar", 2, 2, Style{Bar: 0.5, BarColor: "#638EC6"}, true},
+		{"largest bar", 3, 2, Style{Bar: 1, BarColor: "#638EC6"}, true},
+		{"blank has no bar", 4, 2, Style{}, false},
+	}
+	for _, tt := range tests {
+		got, found := f.At(tt.row, tt.col)
+		if got != tt.want || found != tt.found {
+			t.Errorf("%s: At(%d, %d) = %+v, %v, want %+v, %v", tt.name, tt.row, tt.col, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestAtTopBottom(t *testing.T) {
+	sheet := models.NewSheet("S", [][]string{{"5"}, {"1"}, {"9"}, {"3"}, {"7"}})
+	column := []models.CellRange{{FirstRow: 0, FirstCol: 0, LastRow: -1, LastCol: 0}}
+	value := func(row, col int) string { return sheet.CellAt(row, col).Value }
+
+	tests := []struct {
+		name string
+		rule models.FormatRule
+		want []bool // Whether each row is styled
+	}{
+		{"top 2", models.FormatRule{Rank: 2}, []bool{false, false, true, false, true}},
+		{"bottom 1", models.FormatRule{Rank: 1, Bottom: true}, []bool{false, true, false, false, false}},
+		{"top 40 percent", models.FormatRule{Rank: 40, Percent: true}, []bool{false, false, true, false, true}},
+		{"rank beyond the values", models.FormatRule{Rank: 10}, []bool{true, true, true, true, true}},
+	}
+	for _, tt := range tests {
+		tt.rule.Kind, tt.rule.Ranges, tt.rule.Fg = models.FormatTopBottom, column, "accent"
+		sheet.Formats = []models.FormatRule{tt.rule}
+		f := New(&sheet, value)
+		for row, want := range tt.want {
+			if _, found := f.At(row, 0); found != want {
+				t.Errorf("%s: row %d styled = %v, want %v", tt.name, row, found, want)
+			}
+		}
+	}
+}
+
+func TestNilFormatter(t *testing.T) {
+	sheet := models.NewSheet("S", [][]string{{"1"}})
+	f := New(&sheet, func(row, col int) string { return "" })
+	if f != nil {
+		t.Fatal("New returned a formatter for a sheet without rules")
+	}
+	if _, found := f.At(0, 0); found {
+		t.Error("nil formatter styled a cell")
+	}
+}
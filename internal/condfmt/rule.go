@@ -0,0 +1,192 @@
+package condfmt
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/vex/internal/loader"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+// ThemeColors are the theme colour names a rule can use
+var ThemeColors = []string{"error", "warning", "success", "accent", "primary", "secondary"}
+
+// ParseRule parses a rule such as `Profit Margin < 30% -> error`: a column
+// named by its header or letter, a filter condition and the colour of the
+// matching cells, optionally followed by `on` and a background colour. The
+// rule covers the column below the header row.
+func ParseRule(text string, headers []string, headerRow int) (models.FormatRule, error) {
+	var rule models.FormatRule
+	left, right, ok := strings.Cut(text, "->")
+	if !ok {
+		left, right, ok = strings.Cut(text, "→")
+	}
+	if !ok {
+		return rule, fmt.Errorf("missing -> and a colour")
+	}
+
+	fg, fill, _ := strings.Cut(strings.TrimSpace(right), " on ")
+	fg, fill = strings.ToLower(strings.TrimSpace(fg)), strings.ToLower(strings.TrimSpace(fill))
+	for _, c := range []string{fg, fill} {
+		if c != "" && !IsColor(c) {
+			return rule, fmt.Errorf("unknown colour %q (use %s or #RRGGBB)", c, strings.Join(ThemeColors, ", "))
+		}
+	}
+	if fg == "" {
+		return rule, fmt.Errorf("missing colour after ->")
+	}
+
+	col, cond, err := splitColumn(strings.TrimSpace(left), headers)
+	if err != nil {
+		return rule, err
+	}
+	if strings.TrimSpace(cond) == "" {
+		return rule, fmt.Errorf("missing condition")
+	}
+	op, operand, err := loader.ParseCondition(cond)
+	if err != nil {
+		return rule, err
+	}
+
+	return models.FormatRule{
+		Kind:   models.FormatCell,
+		Ranges: []models.CellRange{{FirstRow: headerRow + 1, FirstCol: col, LastRow: -1, LastCol: col}},
+		Op:     op,
+		Value:  operand,
+		Fg:     normColor(fg),
+		Fill:   normColor(fill),
+		Rule:   strings.TrimSpace(text),
+	}, nil
+}
+
+// splitColumn splits the column a rule applies to off its condition. A
+// quoted name or the longest matching header wins over a column letter.
+func splitColumn(text string, headers []string) (int, string, error) {
+	if rest, ok := strings.CutPrefix(text, `"`); ok {
+		name, cond, ok := strings.Cut(rest, `"`)
+		if !ok {
+			return 0, "", fmt.Errorf("unterminated quote")
+		}
+		col := loader.HeaderIndex(headers, name)
+		if col < 0 {
+			return 0, "", fmt.Errorf("unknown column %q", name)
+		}
+		return col, cond, nil
+	}
+
+	best, bestLen := -1, 0
+	lower := strings.ToLower(text)
+	for col, h := range headers {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h == "" || len(h) <= bestLen || !strings.HasPrefix(lower, h) {
+			continue
+		}
+		if next := []rune(text[len(h):]); len(next) > 0 && (unicode.IsLetter(next[0]) || unicode.IsDigit(next[0])) {
+			continue
+		}
+		best, bestLen = col, len(h)
+	}
+	if best >= 0 {
+		return best, text[bestLen:], nil
+	}
+
+	word := strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) })
+	if len(word) > 0 && strings.HasPrefix(text, word[0]) {
+		if col := ui.LetterToColIndex(word[0]); col >= 0 && col < len(headers) {
+			return col, text[len(word[0]):], nil
+		}
+	}
+	return 0, "", fmt.Errorf("no column matches %q", text)
+}
+
+// IsColor reports whether a rule colour is a theme colour name or #RRGGBB
+func IsColor(c string) bool {
+	for _, name := range ThemeColors {
+		if strings.EqualFold(c, name) {
+			return true
+		}
+	}
+	if len(c) != 7 || c[0] != '#' {
+		return false
+	}
+	for _, r := range c[1:] {
+		if !unicode.Is(unicode.ASCII_Hex_Digit, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// normColor writes theme colour names in lower case and RGB colours in
+// upper case
+func normColor(c string) string {
+	if strings.HasPrefix(c, "#") {
+		return strings.ToUpper(c)
+	}
+	return strings.ToLower(c)
+}
+
+// Describe summarises a rule for the list of rules
+func Describe(r models.FormatRule) string {
+	if r.Rule != "" {
+		return r.Rule
+	}
+	switch r.Kind {
+	case models.FormatCell:
+		cond := loader.FormatCondition(r.Op, r.Value)
+		switch r.Op {
+		case "between":
+			cond = fmt.Sprintf("between %s and %s", r.Value, r.Value2)
+		case "notBetween":
+			cond = fmt.Sprintf("not between %s and %s", r.Value, r.Value2)
+		}
+		return cond + " → " + colors(r.Fg, r.Fill)
+	case models.FormatTopBottom:
+		side := "top"
+		if r.Bottom {
+			side = "bottom"
+		}
+		n := fmt.Sprint(r.Rank)
+		if r.Percent {
+			n += "%"
+		}
+		return side + " " + n + " → " + colors(r.Fg, r.Fill)
+	case models.FormatColorScale:
+		stops := make([]string, len(r.Stops))
+		for i, s := range r.Stops {
+			stops[i] = s.Color
+		}
+		return "colour scale " + strings.Join(stops, " … ")
+	case models.FormatDataBar:
+		return "data bar " + r.Fill
+	}
+	return ""
+}
+
+// colors describes the text and background colours of a rule
+func colors(fg, fill string) string {
+	switch {
+	case fill == "":
+		return fg
+	case fg == "":
+		return "on " + fill
+	}
+	return fg + " on " + fill
+}
+
+// RangeLabel shows the ranges of a rule as cell references such as A2:A10,
+// or A2:A for a range that runs to the end of the sheet
+func RangeLabel(r models.FormatRule) string {
+	refs := make([]string, len(r.Ranges))
+	for i, rg := range r.Ranges {
+		first := fmt.Sprintf("%s%d", ui.ColIndexToLetter(rg.FirstCol), rg.FirstRow+1)
+		last := ui.ColIndexToLetter(rg.LastCol)
+		if rg.LastRow >= 0 {
+			last += fmt.Sprint(rg.LastRow + 1)
+		}
+		refs[i] = first + ":" + last
+	}
+	return strings.Join(refs, " ")
+}
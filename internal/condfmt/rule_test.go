@@ -0,0 +1,66 @@
+package condfmt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vex/pkg/models"
+)
+
+func TestParseRule(t *testing.T) {
+	headers := []string{"Region", "Profit", "Profit Margin"}
+	tests := []struct {
+		text string
+		want models.FormatRule
+	}{
+		{"Profit Margin < 30% -> error", models.FormatRule{
+			Kind:   models.FormatCell,
+			Ranges: []models.CellRange{{FirstRow: 2, FirstCol: 2, LastRow: -1, LastCol: 2}},
+			Op:     "<", Value: "30%", Fg: "error", Rule: "Profit Margin < 30% -> error",
+		}},
+		{"profit >= 1000 → Success on #00ff00", models.FormatRule{
+			Kind:   models.FormatCell,
+			Ranges: []models.CellRange{{FirstRow: 2, FirstCol: 1, LastRow: -1, LastCol: 1}},
+			Op:     ">=", Value: "1000", Fg: "success", Fill: "#00FF00", Rule: "profit >= 1000 → Success on #00ff00",
+		}},
+		{`"Region" contains north -> accent`, models.FormatRule{
+			Kind:   models.FormatCell,
+			Ranges: []models.CellRange{{FirstRow: 2, FirstCol: 0, LastRow: -1, LastCol: 0}},
+			Op:     "contains", Value: "north", Fg: "accent", Rule: `"Region" contains north -> accent`,
+		}},
+		{"A blank -> warning", models.FormatRule{
+			Kind:   models.FormatCell,
+			Ranges: []models.CellRange{{FirstRow: 2, FirstCol: 0, LastRow: -1, LastCol: 0}},
+			Op:     "blank", Fg: "warning", Rule: "A blank -> warning",
+		}},
+	}
+	for _, tt := range tests {
+		got, err := ParseRule(tt.text, headers, 1)
+		if err != nil {
+			t.Errorf("ParseRule(%q) error: %v", tt.text, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseRule(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseRuleErrors(t *testing.T) {
+	headers := []string{"Region", "Profit"}
+	tests := []string{
+		"Profit > 5",
+		"Profit > 5 ->",
+		"Profit > 5 -> pink",
+		"Profit > 5 -> error on #12345",
+		"Cost > 5 -> error",
+		`"Profit > 5 -> error`,
+		"Profit -> error",
+		"Z > 5 -> error",
+	}
+	for _, text := range tests {
+		if _, err := ParseRule(text, headers, 0); err == nil {
+			t.Errorf("ParseRule(%q) succeeded, want an error", text)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Package config loads user preferences and locates per-user directories.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// appName is the directory name used under the XDG base directories
+const appName = "vex"
+
+// Config holds user preferences read from config.json
+type Config struct {
+	// Clipboard forces a clipboard backend: "auto", "system" or "osc52"
+	Clipboard string `json:"clipboard"`
+	// ClipboardTmux wraps OSC 52 sequences for tmux passthrough even when
+	// $TMUX is not set, e.g. when running inside tmux over SSH
+	ClipboardTmux bool `json:"clipboard_tmux"`
+}
+
+// Default returns the configuration used when no file exists
+func Default() Config {
+	return Config{Clipboard: "auto"}
+}
+
+// Load reads the user configuration file. A missing file is not an error.
+func Load() (Config, error) {
+	cfg := Default()
+
+	path := filepath.Join(ConfigDir(), "config.json")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read config: %w", err)
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Default(), fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ConfigDir returns the vex directory under $XDG_CONFIG_HOME
+func ConfigDir() string {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// StateDir returns the vex directory under $XDG_STATE_HOME
+func StateDir() string {
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// xdgDir resolves an XDG base directory, falling back to a path under the
+// user's home directory when the variable is unset or relative
+func xdgDir(env, fallback string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), appName)
+	}
+	return filepath.Join(home, fallback, appName)
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// maxHistory bounds the entries kept for each input
+const maxHistory = 500
+
+// History holds the recent entries of each prompt, keyed by prompt name
+// ("search", "jump", "export") with the most recent entry last
+type History map[string][]string
+
+// historyPath returns the file the input history is stored in
+func historyPath() string {
+	return filepath.Join(StateDir(), "history.json")
+}
+
+// LoadHistory reads the input history. A missing file is not an error.
+func LoadHistory() (History, error) {
+	h := make(History)
+	data, err := os.ReadFile(historyPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return h, fmt.Errorf("failed to read history: %w", err)
+	}
+	if err := json.Unmarshal(data, &h); err != nil {
+		return make(History), fmt.Errorf("failed to parse history: %w", err)
+	}
+	return h, nil
+}
+
+// Add records an entry for a prompt, moving a repeated entry to the end
+func (h History) Add(name, entry string) {
+	entries := h[name]
+	for i, e := range entries {
+		if e == entry {
+			entries = append(entries[:i], entries[i+1:]...)
+			break
+		}
+	}
+	entries = append(entries, entry)
+	if len(entries) > maxHistory {
+		entries = entries[len(entries)-maxHistory:]
+	}
+	h[name] = entries
+}
+
+// Save writes the input history, replacing the file atomically
+func (h History) Save() error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode history: %w", err)
+	}
+	if err := writeState(historyPath(), data); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// writeState replaces a file in the state directory atomically
+func writeState(path string, data []byte) error {
+	if err := os.MkdirAll(StateDir(), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(StateDir(), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Session is what vex remembers about a file between runs
+type Session struct {
+	// Formats holds the conditional format rules defined in vex, by sheet
+	Formats map[string][]string `json:"formats,omitempty"`
+}
+
+// Sessions holds the session of each file, keyed by absolute path
+type Sessions map[string]Session
+
+// sessionsPath returns the file the sessions are stored in
+func sessionsPath() string {
+	return filepath.Join(StateDir(), "sessions.json")
+}
+
+// SessionKey returns the key a file's session is stored under
+func SessionKey(filename string) string {
+	if abs, err := filepath.Abs(filename); err == nil {
+		return abs
+	}
+	return filename
+}
+
+// LoadSessions reads the sessions. A missing file is not an error.
+func LoadSessions() (Sessions, error) {
+	s := make(Sessions)
+	data, err := os.ReadFile(sessionsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("failed to read sessions: %w", err)
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return make(Sessions), fmt.Errorf("failed to parse sessions: %w", err)
+	}
+	return s, nil
+}
+
+// Save writes the sessions, replacing the file atomically
+func (s Sessions) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sessions: %w", err)
+	}
+	if err := writeState(sessionsPath(), data); err != nil {
+		return fmt.Errorf("failed to write sessions: %w", err)
+	}
+	return nil
+}
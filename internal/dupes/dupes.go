@@ -0,0 +1,132 @@
+// Package dupes finds rows of a sheet that repeat the values of another.
+package dupes
+
+import (
+	"strings"
+
+	"github.com/vex/pkg/models"
+)
+
+// Options selects what makes two rows duplicates
+type Options struct {
+	Cols       []int // Key columns; empty compares whole rows
+	IgnoreCase bool
+	HeaderRow  int // Rows up to and including it are never compared
+}
+
+// Keep is which rows of each duplicate group remain when removing
+// duplicates
+type Keep int
+
+const (
+	KeepFirst Keep = iota
+	KeepLast
+	KeepNone // Drop every row that has a duplicate
+)
+
+func (k Keep) String() string {
+	switch k {
+	case KeepLast:
+		return "keep last"
+	case KeepNone:
+		return "drop all"
+	}
+	return "keep first"
+}
+
+// Group is a set of rows with equal keys, in sheet order
+type Group struct {
+	Rows []int
+}
+
+// keySep joins the values of several columns into one map key
+const keySep = "\x00"
+
+// Find returns the groups of two or more rows below the header with equal
+// values in the key columns, ordered by their first row. Rows skip
+// excludes and rows that are blank in every key column are ignored.
+func Find(sheet *models.Sheet, opts Options, skip func(row int) bool) []Group {
+	cols := opts.Cols
+	if len(cols) == 0 {
+		cols = make([]int, sheet.MaxCols)
+		for i := range cols {
+			cols[i] = i
+		}
+	}
+
+	index := make(map[string]int)
+	var groups []Group
+	values := make([]string, len(cols))
+	for row := opts.HeaderRow + 1; row < sheet.MaxRows; row++ {
+		if skip != nil && skip(row) {
+			continue
+		}
+		blank := true
+		for i, col := range cols {
+			v := strings.TrimSpace(sheet.CellAt(row, col).Value)
+			if opts.IgnoreCase {
+				v = strings.ToLower(v)
+			}
+			values[i] = v
+			blank = blank && v == ""
+		}
+		if blank {
+			continue
+		}
+
+		key := strings.Join(values, keySep)
+		if i, ok := index[key]; ok {
+			groups[i].Rows = append(groups[i].Rows, row)
+			continue
+		}
+		index[key] = len(groups)
+		groups = append(groups, Group{Rows: []int{row}})
+	}
+
+	dupes := groups[:0]
+	for _, g := range groups {
+		if len(g.Rows) > 1 {
+			dupes = append(dupes, g)
+		}
+	}
+	return dupes
+}
+
+// Rows returns the set of rows that belong to any group
+func Rows(groups []Group) map[int]bool {
+	rows := make(map[int]bool)
+	for _, g := range groups {
+		for _, r := range g.Rows {
+			rows[r] = true
+		}
+	}
+	return rows
+}
+
+// Remove returns the values of the rows skip does not exclude, leaving out
+// the duplicates keep does not keep. The header rows are kept.
+func Remove(sheet *models.Sheet, groups []Group, keep Keep, headerRow int, skip func(row int) bool) [][]string {
+	drop := make(map[int]bool)
+	for _, g := range groups {
+		for i, r := range g.Rows {
+			switch {
+			case keep == KeepFirst && i == 0, keep == KeepLast && i == len(g.Rows)-1:
+			default:
+				drop[r] = true
+			}
+		}
+	}
+
+	var out [][]string
+	for row := 0; row < sheet.MaxRows; row++ {
+		if row > headerRow && (drop[row] || (skip != nil && skip(row))) {
+			continue
+		}
+		values := make([]string, sheet.MaxCols)
+		for col := range values {
+			values[col] = sheet.CellAt(row, col).Value
+		}
+		out = append(out, values)
+	}
+	return out
+}
@@ -0,0 +1,69 @@
+package dupes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vex/pkg/models"
+)
+
+func testSheet() models.Sheet {
+	return models.NewSheet("S", [][]string{
+		{"Name", "City"},
+		{"Ann", "Oslo"},
+		{"Bob", "Rome"},
+		{"ann", "Oslo"},
+		{"Ann", "Oslo "},
+		{"", ""},
+		{"Bob", "Lima"},
+		{"", ""},
+	})
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		skip func(row int) bool
+		want []Group
+	}{
+		{"whole rows", Options{}, nil, []Group{{Rows: []int{1, 4}}}},
+		{"ignore case", Options{IgnoreCase: true}, nil, []Group{{Rows: []int{1, 3, 4}}}},
+		{"key column", Options{Cols: []int{0}}, nil, []Group{{Rows: []int{1, 4}}, {Rows: []int{2, 6}}}},
+		{"skipped rows", Options{Cols: []int{0}}, func(row int) bool { return row == 4 }, []Group{{Rows: []int{2, 6}}}},
+		{"header row", Options{Cols: []int{1}, HeaderRow: 2}, nil, []Group{{Rows: []int{3, 4}}}},
+		{"no duplicates", Options{Cols: []int{0, 1}, HeaderRow: 4}, nil, []Group{}},
+	}
+	for _, tt := range tests {
+		sheet := testSheet()
+		got := Find(&sheet, tt.opts, tt.skip)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Find = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRemove(t *testing.T) {
+	groups := []Group{{Rows: []int{1, 3, 4}}}
+	tests := []struct {
+		name string
+		keep Keep
+		skip func(row int) bool
+		want []string // First column of the rows left
+	}{
+		{"keep first", KeepFirst, nil, []string{"Name", "Ann", "Bob", "", "Bob", ""}},
+		{"keep last", KeepLast, nil, []string{"Name", "Bob", "Ann", "", "Bob", ""}},
+		{"drop all", KeepNone, nil, []string{"Name", "Bob", "", "Bob", ""}},
+		{"skipped rows", KeepFirst, func(row int) bool { return row == 0 || row == 2 }, []string{"Name", "Ann", "", "Bob", ""}},
+	}
+	for _, tt := range tests {
+		sheet := testSheet()
+		var got []string
+		for _, row := range Remove(&sheet, groups, tt.keep, 0, tt.skip) {
+			got = append(got, row[0])
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Remove = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
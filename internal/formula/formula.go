@@ -0,0 +1,302 @@
+// Package formula rewrites cell references inside spreadsheet formulas.
+package formula
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RefError is the text Excel uses for a reference to a deleted cell
+const RefError = "#REF!"
+
+// Axis selects whether references are remapped by row or by column
+type Axis int
+
+const (
+	Rows Axis = iota
+	Cols
+)
+
+// Mapper maps a 0-indexed row or column to its new index. It returns
+// false if the row or column no longer exists.
+type Mapper func(index int) (int, bool)
+
+// refPattern matches an optional sheet prefix followed by an A1 reference,
+// an A1:B2 range, a whole-column range such as A:C or a whole-row range
+// such as 2:5. The sheet prefix is either 'quoted name'! or name!.
+var refPattern = regexp.MustCompile(`((?:'(?:[^']|'')+'|[A-Za-z_][\w.]*)!)?(?:(\$?[A-Za-z]{1,3}\$?\d+)(?::(\$?[A-Za-z]{1,3}\$?\d+))?|(\$?[A-Za-z]{1,3}):(\$?[A-Za-z]{1,3})|(\$?\d+):(\$?\d+))`)
+
+var cellPattern = regexp.MustCompile(`^(\$?)([A-Za-z]{1,3})(\$?)(\d+)$`)
+
+// Remap rewrites references to sheet in f using mapFn along axis.
+// Unqualified references are treated as pointing to the formula's own
+// sheet, whose name is given by own.
+func Remap(f, own, sheet string, axis Axis, mapFn Mapper) string {
+	if f == "" {
+		return f
+	}
+
+	var b strings.Builder
+	for i, segment := range splitStrings(f) {
+		// Odd segments are string literals and are kept verbatim
+		if i%2 == 1 {
+			b.WriteString(segment)
+			continue
+		}
+		b.WriteString(remapSegment(segment, own, sheet, axis, mapFn))
+	}
+	return b.String()
+}
+
+// remapSegment rewrites references in a part of a formula without string literals
+func remapSegment(s, own, sheet string, axis Axis, mapFn Mapper) string {
+	matches := refPattern.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range matches {
+		start, end := loc[0], loc[1]
+		// Skip function names such as LOG10( and parts of longer identifiers
+		if end < len(s) && (s[end] == '(' || isIdentChar(s[end])) {
+			continue
+		}
+		if start > 0 && (isIdentChar(s[start-1]) || s[start-1] == '$') {
+			continue
+		}
+
+		target := own
+		if loc[2] >= 0 {
+			target = unquoteSheet(s[loc[2] : loc[3]-1])
+		}
+		if !strings.EqualFold(target, sheet) {
+			continue
+		}
+
+		b.WriteString(s[last:start])
+		prefix := ""
+		if loc[2] >= 0 {
+			prefix = s[loc[2]:loc[3]]
+		}
+
+		switch {
+		case loc[8] >= 0:
+			b.WriteString(remapLines(prefix, s[loc[8]:loc[9]], s[loc[10]:loc[11]], axis == Cols, mapFn))
+			last = end
+			continue
+		case loc[12] >= 0:
+			b.WriteString(remapLines(prefix, s[loc[12]:loc[13]], s[loc[14]:loc[15]], axis == Rows, mapFn))
+			last = end
+			continue
+		}
+
+		first := s[loc[4]:loc[5]]
+		if loc[6] < 0 {
+			if ref, ok := remapCell(first, axis, mapFn, 0); ok {
+				b.WriteString(prefix + ref)
+			} else {
+				b.WriteString(RefError)
+			}
+		} else {
+			second := s[loc[6]:loc[7]]
+			startRef, ok1 := remapCell(first, axis, mapFn, 1)
+			endRef, ok2 := remapCell(second, axis, mapFn, -1)
+			if ok1 && ok2 && cellIndex(startRef, axis) <= cellIndex(endRef, axis) {
+				b.WriteString(prefix + startRef + ":" + endRef)
+			} else {
+				b.WriteString(RefError)
+			}
+		}
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// remapCell remaps a single A1 reference. When the referenced row or
+// column was removed and step is non-zero, the nearest surviving index in
+// the direction of step is used instead, which shrinks ranges.
+func remapCell(ref string, axis Axis, mapFn Mapper, step int) (string, bool) {
+	parts := cellPattern.FindStringSubmatch(ref)
+	if parts == nil {
+		return ref, true
+	}
+	row, err := strconv.Atoi(parts[4])
+	if err != nil {
+		return ref, true
+	}
+	col := colIndex(parts[2])
+
+	index := row - 1
+	if axis == Cols {
+		index = col
+	}
+
+	newIndex, ok := mapFn(index)
+	for tries := 0; !ok && step != 0 && tries < 1<<16; tries++ {
+		index += step
+		if index < 0 {
+			break
+		}
+		newIndex, ok = mapFn(index)
+	}
+	if !ok {
+		return "", false
+	}
+
+	if axis == Rows {
+		row = newIndex + 1
+	} else {
+		col = newIndex
+	}
+	return parts[1] + colLetters(col) + parts[3] + strconv.Itoa(row), true
+}
+
+// remapLines remaps a whole-column range such as $B:D or a whole-row range
+// such as 2:5. Ranges along the other axis are kept as they are; removed
+// columns or rows shrink the range as they do for cell ranges.
+func remapLines(prefix, first, second string, onAxis bool, mapFn Mapper) string {
+	if !onAxis {
+		return prefix + first + ":" + second
+	}
+	start, ok1 := remapLine(first, mapFn, 1)
+	end, ok2 := remapLine(second, mapFn, -1)
+	if !ok1 || !ok2 || lineIndex(start) > lineIndex(end) {
+		return RefError
+	}
+	return prefix + start + ":" + end
+}
+
+// remapLine remaps one end of a whole-column or whole-row range, moving
+// in the direction of step past removed columns or rows
+func remapLine(ref string, mapFn Mapper, step int) (string, bool) {
+	dollar, name := "", ref
+	if strings.HasPrefix(ref, "$") {
+		dollar, name = "$", ref[1:]
+	}
+	index := lineIndex(ref)
+	newIndex, ok := mapFn(index)
+	for tries := 0; !ok && tries < 1<<16; tries++ {
+		index += step
+		if index < 0 {
+			break
+		}
+		newIndex, ok = mapFn(index)
+	}
+	if !ok {
+		return "", false
+	}
+	if _, err := strconv.Atoi(name); err == nil {
+		return dollar + strconv.Itoa(newIndex+1), true
+	}
+	return dollar + colLetters(newIndex), true
+}
+
+// lineIndex returns the 0-indexed column of a column reference such as $B
+// or the row of a row reference such as 5
+func lineIndex(ref string) int {
+	name := strings.TrimPrefix(ref, "$")
+	if row, err := strconv.Atoi(name); err == nil {
+		return row - 1
+	}
+	return colIndex(name)
+}
+
+// cellIndex returns the row or column index of an A1 reference
+func cellIndex(ref string, axis Axis) int {
+	parts := cellPattern.FindStringSubmatch(ref)
+	if parts == nil {
+		return 0
+	}
+	if axis == Cols {
+		return colIndex(parts[2])
+	}
+	row, _ := strconv.Atoi(parts[4])
+	return row - 1
+}
+
+// splitStrings splits a formula into alternating non-literal and
+// double-quoted string literal segments
+func splitStrings(f string) []string {
+	var segments []string
+	inString := false
+	start := 0
+	for i := 0; i < len(f); i++ {
+		if f[i] != '"' {
+			continue
+		}
+		if inString {
+			// A doubled quote is an escaped quote inside the literal
+			if i+1 < len(f) && f[i+1] == '"' {
+				i++
+				continue
+			}
+			segments = append(segments, f[start:i+1])
+			start = i + 1
+		} else {
+			segments = append(segments, f[start:i])
+			start = i
+		}
+		inString = !inString
+	}
+	segments = append(segments, f[start:])
+	return segments
+}
+
+func unquoteSheet(name string) string {
+	if strings.HasPrefix(name, "'") && strings.HasSuffix(name, "'") && len(name) >= 2 {
+		return strings.ReplaceAll(name[1:len(name)-1], "''", "'")
+	}
+	return name
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '.' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func colIndex(letters string) int {
+	col := 0
+	for _, r := range strings.ToUpper(letters) {
+		col = col*26 + int(r-'A') + 1
+	}
+	return col - 1
+}
+
+func colLetters(index int) string {
+	result := ""
+	for index >= 0 {
+		result = string(rune('A'+index%26)) + result
+		index = index/26 - 1
+	}
+	return result
+}
+
+// Shift returns a Mapper for inserting (delta > 0) or deleting (delta < 0)
+// rows or columns at index at
+func Shift(at, delta int) Mapper {
+	return func(i int) (int, bool) {
+		if i < at {
+			return i, true
+		}
+		if delta < 0 && i < at-delta {
+			return 0, false
+		}
+		return i + delta, true
+	}
+}
+
+// Swap returns a Mapper that exchanges two rows or columns
+func Swap(a, b int) Mapper {
+	return func(i int) (int, bool) {
+		switch i {
+		case a:
+			return b, true
+		case b:
+			return a, true
+		}
+		return i, true
+	}
+}
@@ -0,0 +1,68 @@
+package formula
+
+import "testing"
+
+func TestShift(t *testing.T) {
+	tests := []struct {
+		at, delta int
+		in, want  int
+		ok        bool
+	}{
+		{at: 3, delta: 2, in: 2, want: 2, ok: true},
+		{at: 3, delta: 2, in: 3, want: 5, ok: true},
+		{at: 3, delta: 2, in: 9, want: 11, ok: true},
+		{at: 3, delta: -2, in: 2, want: 2, ok: true},
+		{at: 3, delta: -2, in: 3, ok: false},
+		{at: 3, delta: -2, in: 4, ok: false},
+		{at: 3, delta: -2, in: 5, want: 3, ok: true},
+		{at: 0, delta: -1, in: 0, ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := Shift(tt.at, tt.delta)(tt.in)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("Shift(%d, %d)(%d) = %d, %v, want %d, %v", tt.at, tt.delta, tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSwap(t *testing.T) {
+	tests := []struct {
+		a, b, in, want int
+	}{
+		{a: 2, b: 3, in: 2, want: 3},
+		{a: 2, b: 3, in: 3, want: 2},
+		{a: 2, b: 3, in: 1, want: 1},
+		{a: 2, b: 3, in: 4, want: 4},
+		{a: 5, b: 5, in: 5, want: 5},
+	}
+	for _, tt := range tests {
+		if got, ok := Swap(tt.a, tt.b)(tt.in); !ok || got != tt.want {
+			t.Errorf("Swap(%d, %d)(%d) = %d, %v, want %d", tt.a, tt.b, tt.in, got, ok, tt.want)
+		}
+	}
+}
+
+func TestRemap(t *testing.T) {
+	tests := []struct {
+		name  string
+		f     string
+		own   string
+		axis  Axis
+		mapFn Mapper
+		want  string
+	}{
+		{"insert rows", "=A1+A3*$B$4", "Data", Rows, Shift(1, 2), "=A1+A5*$B$6"},
+		{"delete referenced row", "=A2+A3", "Data", Rows, Shift(1, -1), "=#REF!+A2"},
+		{"range spanning a delete", "=SUM(A1:A5)", "Data", Rows, Shift(1, -2), "=SUM(A1:A3)"},
+		{"insert columns", "=SUM(B:C)+D1", "Data", Cols, Shift(1, 1), "=SUM(C:D)+E1"},
+		{"swap rows", "=A2-A3", "Data", Rows, Swap(1, 2), "=A3-A2"},
+		{"other sheet untouched", "=A3+Other!A3", "Other", Rows, Shift(1, 2), "=A3+Other!A3"},
+		{"qualified reference", "=A3+Data!A3", "Other", Rows, Shift(1, 2), "=A3+Data!A5"},
+		{"string literal kept", `=A3&"A3"`, "Data", Rows, Shift(1, 2), `=A5&"A3"`},
+	}
+	for _, tt := range tests {
+		if got := Remap(tt.f, tt.own, "Data", tt.axis, tt.mapFn); got != tt.want {
+			t.Errorf("%s: Remap(%q) = %q, want %q", tt.name, tt.f, got, tt.want)
+		}
+	}
+}
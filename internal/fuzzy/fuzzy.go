@@ -0,0 +1,133 @@
+// Package fuzzy ranks text against a typed pattern the way fzf does.
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	scoreMatch       = 16
+	scoreGapStart    = -3
+	scoreGapExtend   = -1
+	bonusBoundary    = 8
+	bonusConsecutive = 4
+	bonusFirstChar   = 8
+)
+
+// Match is a ranked candidate
+type Match struct {
+	Index     int   // Index of the candidate in the searched slice
+	Score     int   // Higher is better
+	Positions []int // Rune offsets of the matched characters
+}
+
+// Score matches pattern against text as an in-order subsequence. Matching
+// is case-insensitive unless the pattern contains an upper case letter.
+// Matches at word boundaries and runs of consecutive characters score
+// higher; gaps between matched characters score lower.
+func Score(pattern, text string) (int, []int, bool) {
+	if pattern == "" {
+		return 0, nil, true
+	}
+	caseSensitive := strings.IndexFunc(pattern, unicode.IsUpper) >= 0
+	pat := []rune(pattern)
+	runes := []rune(text)
+	if !caseSensitive {
+		for i := range pat {
+			pat[i] = unicode.ToLower(pat[i])
+		}
+	}
+
+	fold := func(r rune) rune {
+		if caseSensitive {
+			return r
+		}
+		return unicode.ToLower(r)
+	}
+
+	// Find the first occurrence, then walk back from its end to the
+	// shortest window ending there, like fzf's v1 algorithm
+	p, end := 0, -1
+	for i, r := range runes {
+		if fold(r) == pat[p] {
+			p++
+			if p == len(pat) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+	start := end
+	for p = len(pat) - 1; start >= 0; start-- {
+		if fold(runes[start]) == pat[p] {
+			p--
+			if p < 0 {
+				break
+			}
+		}
+	}
+
+	positions := make([]int, 0, len(pat))
+	score, p, prev := 0, 0, -2
+	for i := start; i <= end && p < len(pat); i++ {
+		if fold(runes[i]) != pat[p] {
+			continue
+		}
+		score += scoreMatch
+		switch {
+		case i == 0:
+			score += bonusFirstChar + bonusBoundary
+		case isBoundary(runes[i-1], runes[i]):
+			score += bonusBoundary
+		}
+		if prev == i-1 {
+			score += bonusConsecutive
+		} else if prev >= 0 {
+			score += scoreGapStart + scoreGapExtend*(i-prev-2)
+		}
+		positions = append(positions, i)
+		prev = i
+		p++
+	}
+	return score, positions, true
+}
+
+// Rank scores every candidate against pattern and returns the matches,
+// best first. Ties go to the shorter candidate, then the earlier one. An
+// empty pattern matches every candidate in its original order.
+func Rank(pattern string, candidates []string) []Match {
+	matches := make([]Match, 0, len(candidates))
+	if pattern == "" {
+		for i := range candidates {
+			matches = append(matches, Match{Index: i})
+		}
+		return matches
+	}
+
+	for i, text := range candidates {
+		if score, positions, ok := Score(pattern, text); ok {
+			matches = append(matches, Match{Index: i, Score: score, Positions: positions})
+		}
+	}
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].Score != matches[b].Score {
+			return matches[a].Score > matches[b].Score
+		}
+		return len(candidates[matches[a].Index]) < len(candidates[matches[b].Index])
+	})
+	return matches
+}
+
+// isBoundary reports whether cur starts a word after prev
+func isBoundary(prev, cur rune) bool {
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return unicode.IsLetter(cur) || unicode.IsDigit(cur)
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(cur) ||
+		unicode.IsLetter(prev) && unicode.IsDigit(cur)
+}
@@ -0,0 +1,223 @@
+package loader
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/vex/pkg/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxAutoFilter is the part of a worksheet's <autoFilter> element that
+// can be shown as a filter. excelize can write autofilters but not read them.
+type xlsxAutoFilter struct {
+	Ref     string `xml:"ref,attr"`
+	Columns []struct {
+		ColID   int `xml:"colId,attr"`
+		Filters *struct {
+			Blank  bool `xml:"blank,attr"`
+			Values []struct {
+				Val string `xml:"val,attr"`
+			} `xml:"filter"`
+		} `xml:"filters"`
+		CustomFilters *struct {
+			Filters []struct {
+				Operator string `xml:"operator,attr"`
+				Val      string `xml:"val,attr"`
+			} `xml:"customFilter"`
+		} `xml:"customFilters"`
+	} `xml:"filterColumn"`
+}
+
+// customOps maps the operators of an xlsx custom filter to filter conditions
+var customOps = map[string]string{
+	"":                   "=",
+	"equal":              "=",
+	"notEqual":           "!=",
+	"greaterThan":        ">",
+	"greaterThanOrEqual": ">=",
+	"lessThan":           "<",
+	"lessThanOrEqual":    "<=",
+}
+
+// worksheetPart is what vex reads from a worksheet part itself rather than
+// through excelize
+type worksheetPart struct {
+	filter  *models.AutoFilter
+	formats []xlsxConditionalFormatting
+}
+
+// readWorksheetParts returns the autofilter and conditional formats of each
+// sheet of an xlsx file keyed by sheet name. excelize does not expose
+// autofilters and cannot read every conditional format, so the worksheet
+// parts are read directly from the archive.
+func readWorksheetParts(filename string) (map[string]worksheetPart, error) {
+	zr, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	parts := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		parts[f.Name] = f
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodePart(parts["xl/workbook.xml"], &workbook); err != nil {
+		return nil, err
+	}
+	if err := decodePart(parts["xl/_rels/workbook.xml.rels"], &rels); err != nil {
+		return nil, err
+	}
+
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, r := range rels.Relationships {
+		if strings.HasPrefix(r.Target, "/") {
+			targets[r.ID] = strings.TrimPrefix(r.Target, "/")
+		} else {
+			targets[r.ID] = path.Join("xl", r.Target)
+		}
+	}
+
+	sheets := make(map[string]worksheetPart)
+	for _, s := range workbook.Sheets {
+		part, ok := parts[targets[s.ID]]
+		if !ok {
+			continue
+		}
+		af, formats, err := scanWorksheet(part)
+		if err != nil {
+			return nil, fmt.Errorf("sheet %s: %w", s.Name, err)
+		}
+		ws := worksheetPart{formats: formats}
+		if af != nil {
+			ws.filter = convertAutoFilter(af)
+		}
+		sheets[s.Name] = ws
+	}
+	return sheets, nil
+}
+
+// decodePart unmarshals an XML part of the archive
+func decodePart(f *zip.File, v any) error {
+	if f == nil {
+		return fmt.Errorf("missing workbook part")
+	}
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return xml.NewDecoder(r).Decode(v)
+}
+
+// scanWorksheet scans a worksheet part for its <autoFilter> and
+// <conditionalFormatting> elements, skipping over the cell data and
+// extensions without decoding them
+func scanWorksheet(f *zip.File) (af *xlsxAutoFilter, formats []xlsxConditionalFormatting, err error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Close()
+
+	d := xml.NewDecoder(r)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return af, formats, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "sheetData", "extLst":
+			if err := d.Skip(); err != nil {
+				return nil, nil, err
+			}
+		case "autoFilter":
+			af = new(xlsxAutoFilter)
+			if err := d.DecodeElement(af, &start); err != nil {
+				return nil, nil, err
+			}
+		case "conditionalFormatting":
+			var cf xlsxConditionalFormatting
+			if err := d.DecodeElement(&cf, &start); err != nil {
+				return nil, nil, err
+			}
+			formats = append(formats, cf)
+		}
+	}
+}
+
+// convertAutoFilter turns an xlsx autofilter into a sheet filter. Only the
+// first condition of a custom filter is kept; filter kinds without an
+// equivalent (top 10, dynamic, colour) leave their column unfiltered.
+func convertAutoFilter(af *xlsxAutoFilter) *models.AutoFilter {
+	first := strings.Split(af.Ref, ":")[0]
+	col, row, err := excelize.CellNameToCoordinates(first)
+	if err != nil {
+		col, row = 1, 1
+	}
+
+	filter := &models.AutoFilter{HeaderRow: row - 1}
+	for _, fc := range af.Columns {
+		cf := models.ColumnFilter{Col: col - 1 + fc.ColID}
+		switch {
+		case fc.Filters != nil:
+			cf.Values = make(map[string]bool, len(fc.Filters.Values))
+			for _, v := range fc.Filters.Values {
+				cf.Values[strings.TrimSpace(v.Val)] = true
+			}
+			if fc.Filters.Blank {
+				cf.Values[""] = true
+			}
+		case fc.CustomFilters != nil && len(fc.CustomFilters.Filters) > 0:
+			c := fc.CustomFilters.Filters[0]
+			op, ok := customOps[c.Operator]
+			if !ok {
+				continue
+			}
+			cf.Op, cf.Operand = customCondition(op, c.Val)
+		default:
+			continue
+		}
+		filter.Columns = append(filter.Columns, cf)
+	}
+	return filter
+}
+
+// customCondition maps an xlsx custom filter to a condition. Excel writes
+// "contains" as a wildcard pattern and "non blanks" as not equal to a space.
+func customCondition(op, val string) (string, string) {
+	trimmed := strings.TrimSpace(val)
+	switch {
+	case trimmed == "" && op == "=":
+		return "blank", ""
+	case trimmed == "" && op == "!=":
+		return "nonblank", ""
+	case op == "=" && len(trimmed) > 2 && strings.HasPrefix(trimmed, "*") && strings.HasSuffix(trimmed, "*"):
+		return "contains", trimmed[1 : len(trimmed)-1]
+	}
+	return op, trimmed
+}
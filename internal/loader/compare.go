@@ -0,0 +1,84 @@
+package loader
+
+import (
+	"strings"
+	"unicode"
+)
+
+// CompareValues orders two cell values for sorting. Numbers compare
+// numerically and come before dates, which come before text; text
+// compares naturally ("item2" before "item10") ignoring case. Blank
+// values compare equal to each other and after everything else.
+func CompareValues(a, b string) int {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	rank := func(s string) int {
+		if _, ok := ParseNumber(s); ok {
+			return 0
+		}
+		if _, ok := ParseDate(s); ok {
+			return 1
+		}
+		return 2
+	}
+	ra, rb := rank(a), rank(b)
+	if ra != rb {
+		return ra - rb
+	}
+
+	switch ra {
+	case 0:
+		x, _ := ParseNumber(a)
+		y, _ := ParseNumber(b)
+		return compareFloat(x, y)
+	case 1:
+		x, _ := ParseDate(a)
+		y, _ := ParseDate(b)
+		return x.Compare(y)
+	}
+	return NaturalCompare(a, b)
+}
+
+// NaturalCompare compares strings ignoring case, treating runs of digits
+// as numbers
+func NaturalCompare(a, b string) int {
+	ra, rb := []rune(strings.ToLower(a)), []rune(strings.ToLower(b))
+	i, j := 0, 0
+	for i < len(ra) && j < len(rb) {
+		if unicode.IsDigit(ra[i]) && unicode.IsDigit(rb[j]) {
+			si, sj := i, j
+			for i < len(ra) && unicode.IsDigit(ra[i]) {
+				i++
+			}
+			for j < len(rb) && unicode.IsDigit(rb[j]) {
+				j++
+			}
+			na := strings.TrimLeft(string(ra[si:i]), "0")
+			nb := strings.TrimLeft(string(rb[sj:j]), "0")
+			if len(na) != len(nb) {
+				return len(na) - len(nb)
+			}
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+			continue
+		}
+		if ra[i] != rb[j] {
+			if ra[i] < rb[j] {
+				return -1
+			}
+			return 1
+		}
+		i++
+		j++
+	}
+	return (len(ra) - i) - (len(rb) - j)
+}
@@ -0,0 +1,270 @@
+package loader
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vex/pkg/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// cellOps maps the operators of xlsx cell value rules to format rule
+// operators
+var cellOps = map[string]string{
+	"equal":              "=",
+	"notEqual":           "!=",
+	"greaterThan":        ">",
+	"greaterThanOrEqual": ">=",
+	"lessThan":           "<",
+	"lessThanOrEqual":    "<=",
+	"between":            "between",
+	"notBetween":         "notBetween",
+}
+
+// defaultBarColor is the colour of data bars whose colour is not an RGB value
+const defaultBarColor = "#638EC6"
+
+// xlsxConditionalFormatting is a worksheet's <conditionalFormatting>
+// element. excelize reads these too, but dereferences optional attributes
+// of the rules without checking for them and merges the rules of ranges
+// listed more than once.
+type xlsxConditionalFormatting struct {
+	SQRef string       `xml:"sqref,attr"`
+	Rules []xlsxCfRule `xml:"cfRule"`
+}
+
+// xlsxCfRule is the part of a <cfRule> element that can be shown
+type xlsxCfRule struct {
+	Type       string     `xml:"type,attr"`
+	DxfID      *int       `xml:"dxfId,attr"`
+	Priority   int        `xml:"priority,attr"`
+	Operator   string     `xml:"operator,attr"`
+	Rank       int        `xml:"rank,attr"`
+	Bottom     bool       `xml:"bottom,attr"`
+	Percent    bool       `xml:"percent,attr"`
+	Formula    []string   `xml:"formula"`
+	ColorScale *xlsxScale `xml:"colorScale"`
+	DataBar    *xlsxScale `xml:"dataBar"`
+}
+
+// xlsxScale is the <colorScale> or <dataBar> of a rule
+type xlsxScale struct {
+	Cfvo []struct {
+		Type string `xml:"type,attr"`
+		Val  string `xml:"val,attr"`
+	} `xml:"cfvo"`
+	Color []struct {
+		RGB string `xml:"rgb,attr"`
+	} `xml:"color"`
+}
+
+// readConditionalFormats converts the conditional formats of a sheet that
+// can be shown in the terminal: cell value comparisons, colour scales, data
+// bars and top/bottom rules, highest priority first. Other kinds of rule
+// are left out. Rules that cannot be read are skipped and reported in
+// skipped.
+func readConditionalFormats(f *excelize.File, sheet string, formats []xlsxConditionalFormatting) (rules []models.FormatRule, skipped []string) {
+	var priorities []int
+	for _, cf := range formats {
+		ranges, err := parseRanges(cf.SQRef)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("conditional format on %s: %v", cf.SQRef, err))
+			continue
+		}
+		for _, r := range cf.Rules {
+			rule, ok, err := convertFormat(f, sheet, r)
+			if err != nil {
+				skipped = append(skipped, fmt.Sprintf("conditional format on %s: %v", cf.SQRef, err))
+				continue
+			}
+			if !ok {
+				continue
+			}
+			rule.Ranges = ranges
+			rules = append(rules, rule)
+			priorities = append(priorities, r.Priority)
+		}
+	}
+
+	order := make([]int, len(rules))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return priorities[order[a]] < priorities[order[b]] })
+	sorted := make([]models.FormatRule, len(rules))
+	for i, src := range order {
+		sorted[i] = rules[src]
+	}
+	return sorted, skipped
+}
+
+// convertFormat converts an xlsx rule. It reports false for kinds of rule
+// that cannot be shown and an error for rules missing a part they need.
+func convertFormat(f *excelize.File, sheet string, r xlsxCfRule) (models.FormatRule, bool, error) {
+	var rule models.FormatRule
+	switch r.Type {
+	case "cellIs":
+		op, ok := cellOps[r.Operator]
+		if !ok {
+			return rule, false, nil
+		}
+		if r.DxfID == nil {
+			return rule, false, fmt.Errorf("cellIs rule without a format")
+		}
+		operands := 1
+		if op == "between" || op == "notBetween" {
+			operands = 2
+		}
+		if len(r.Formula) < operands {
+			return rule, false, fmt.Errorf("%s rule without its value", r.Operator)
+		}
+		rule.Kind = models.FormatCell
+		rule.Op = op
+		if rule.Value, ok = formulaValue(f, sheet, r.Formula[0]); !ok {
+			return rule, false, nil
+		}
+		if operands == 2 {
+			if rule.Value2, ok = formulaValue(f, sheet, r.Formula[1]); !ok {
+				return rule, false, nil
+			}
+		}
+		rule.Fg, rule.Fill = dxfColors(f, *r.DxfID)
+
+	case "top10":
+		if r.DxfID == nil {
+			return rule, false, fmt.Errorf("top10 rule without a format")
+		}
+		if r.Rank <= 0 {
+			return rule, false, fmt.Errorf("top10 rule without a rank")
+		}
+		rule.Kind = models.FormatTopBottom
+		rule.Rank = r.Rank
+		rule.Bottom = r.Bottom
+		rule.Percent = r.Percent
+		rule.Fg, rule.Fill = dxfColors(f, *r.DxfID)
+
+	case "colorScale":
+		cs := r.ColorScale
+		if cs == nil || len(cs.Color) < 2 || len(cs.Color) > 3 || len(cs.Cfvo) != len(cs.Color) {
+			return rule, false, fmt.Errorf("colorScale rule without matching stops and colours")
+		}
+		rule.Kind = models.FormatColorScale
+		for i, v := range cs.Cfvo {
+			stop := models.FormatStop{Type: v.Type, Value: v.Val, Color: argbColor(cs.Color[i].RGB)}
+			if !isRGB(stop.Color) {
+				// Theme and indexed colours are not resolved
+				return rule, false, nil
+			}
+			rule.Stops = append(rule.Stops, stop)
+		}
+
+	case "dataBar":
+		db := r.DataBar
+		if db == nil || len(db.Cfvo) < 2 {
+			return rule, false, fmt.Errorf("dataBar rule without its bounds")
+		}
+		rule.Kind = models.FormatDataBar
+		rule.Stops = []models.FormatStop{
+			{Type: db.Cfvo[0].Type, Value: db.Cfvo[0].Val},
+			{Type: db.Cfvo[1].Type, Value: db.Cfvo[1].Val},
+		}
+		rule.Fill = defaultBarColor
+		if len(db.Color) > 0 && isRGB(argbColor(db.Color[0].RGB)) {
+			rule.Fill = argbColor(db.Color[0].RGB)
+		}
+
+	default:
+		return rule, false, nil
+	}
+	return rule, true, nil
+}
+
+// formulaValue evaluates the operand of a cell value rule when it is a
+// number, a string or a reference to a cell of the same sheet
+func formulaValue(f *excelize.File, sheet, formula string) (string, bool) {
+	formula = strings.TrimPrefix(strings.TrimSpace(formula), "=")
+	if _, err := strconv.ParseFloat(formula, 64); err == nil {
+		return formula, true
+	}
+	if len(formula) >= 2 && strings.HasPrefix(formula, `"`) && strings.HasSuffix(formula, `"`) {
+		return strings.ReplaceAll(formula[1:len(formula)-1], `""`, `"`), true
+	}
+	ref := strings.ReplaceAll(formula, "$", "")
+	if _, _, err := excelize.CellNameToCoordinates(ref); err == nil {
+		v, err := f.GetCellValue(sheet, ref)
+		return v, err == nil
+	}
+	return "", false
+}
+
+// dxfColors returns the font and fill colours of a differential format.
+// Formats without an RGB colour are shown in the theme's warning colour.
+func dxfColors(f *excelize.File, id int) (fg, fill string) {
+	if f.Styles != nil && f.Styles.Dxfs != nil && id >= 0 && id < len(f.Styles.Dxfs.Dxfs) {
+		dxf := f.Styles.Dxfs.Dxfs[id]
+		if dxf.Font != nil && dxf.Font.Color != nil {
+			fg = argbColor(dxf.Font.Color.RGB)
+		}
+		if dxf.Fill != nil && dxf.Fill.PatternFill != nil {
+			// A solid fill keeps its colour in bgColor within a dxf
+			if pf := dxf.Fill.PatternFill; pf.BgColor != nil {
+				fill = argbColor(pf.BgColor.RGB)
+			} else if pf.FgColor != nil {
+				fill = argbColor(pf.FgColor.RGB)
+			}
+		}
+	}
+	if fg == "" && fill == "" {
+		fg = "warning"
+	}
+	return fg, fill
+}
+
+// argbColor converts an xlsx ARGB or RGB colour to #RRGGBB
+func argbColor(argb string) string {
+	switch len(argb) {
+	case 8:
+		return "#" + strings.ToUpper(argb[2:])
+	case 6:
+		return "#" + strings.ToUpper(argb)
+	}
+	return ""
+}
+
+// isRGB reports whether a colour is in #RRGGBB form
+func isRGB(color string) bool {
+	if len(color) != 7 || color[0] != '#' {
+		return false
+	}
+	_, err := strconv.ParseUint(color[1:], 16, 32)
+	return err == nil
+}
+
+// parseRanges parses a space-separated list of cell ranges such as
+// "A2:A10 C2:D10"
+func parseRanges(sqref string) ([]models.CellRange, error) {
+	var ranges []models.CellRange
+	for _, ref := range strings.Fields(sqref) {
+		first, last, _ := strings.Cut(ref, ":")
+		if last == "" {
+			last = first
+		}
+		c1, r1, err := excelize.CellNameToCoordinates(first)
+		if err != nil {
+			return nil, err
+		}
+		c2, r2, err := excelize.CellNameToCoordinates(last)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, models.CellRange{
+			FirstRow: min(r1, r2) - 1,
+			FirstCol: min(c1, c2) - 1,
+			LastRow:  max(r1, r2) - 1,
+			LastCol:  max(c1, c2) - 1,
+		})
+	}
+	return ranges, nil
+}
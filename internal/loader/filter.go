@@ -0,0 +1,111 @@
+package loader
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vex/pkg/models"
+)
+
+// conditionOps lists the symbolic operators of a filter condition, longest first
+var conditionOps = []string{">=", "<=", "!=", ">", "<", "="}
+
+// ParseCondition parses a filter condition such as "> 100", "contains
+// north", "blank" or "nonblank". A value without an operator means "=".
+func ParseCondition(text string) (op, operand string, err error) {
+	text = strings.TrimSpace(text)
+	lower := strings.ToLower(text)
+
+	switch {
+	case text == "":
+		return "", "", nil
+	case lower == "blank" || lower == "nonblank":
+		return lower, "", nil
+	case strings.HasPrefix(lower, "contains "):
+		operand = strings.TrimSpace(text[len("contains "):])
+		return "contains", unquote(operand), nil
+	}
+
+	for _, o := range conditionOps {
+		if strings.HasPrefix(text, o) {
+			operand = strings.TrimSpace(text[len(o):])
+			if operand == "" {
+				return "", "", fmt.Errorf("missing value after %q", o)
+			}
+			return o, unquote(operand), nil
+		}
+	}
+	return "=", unquote(text), nil
+}
+
+// FormatCondition is the inverse of ParseCondition
+func FormatCondition(op, operand string) string {
+	switch op {
+	case "", "blank", "nonblank":
+		return op
+	case "contains":
+		return "contains " + operand
+	}
+	return op + " " + operand
+}
+
+// MatchColumnFilter reports whether a cell value passes a column filter
+func MatchColumnFilter(f models.ColumnFilter, value string) bool {
+	value = strings.TrimSpace(value)
+	if f.Values != nil && !f.Values[value] {
+		return false
+	}
+
+	switch f.Op {
+	case "":
+		return true
+	case "blank":
+		return value == ""
+	case "nonblank":
+		return value != ""
+	case "contains":
+		return compareValues(value, ":", f.Operand)
+	}
+	// Like Excel, comparisons other than "not equal" never keep blanks
+	if value == "" {
+		return f.Op == "!=" && f.Operand != ""
+	}
+	return compareValues(value, f.Op, f.Operand)
+}
+
+// ApplyAutoFilter recomputes the rows hidden by the sheet's autofilter.
+// value reads the cell values the filters test; nil reads the stored ones.
+func ApplyAutoFilter(sheet *models.Sheet, value func(row, col int) string) {
+	f := sheet.Filter
+	if f == nil {
+		return
+	}
+
+	f.Hidden = make([]bool, sheet.MaxRows)
+	f.Shown = 0
+	for row := f.HeaderRow + 1; row < sheet.MaxRows; row++ {
+		if RowPasses(sheet, value, row, -1) {
+			f.Shown++
+		} else {
+			f.Hidden[row] = true
+		}
+	}
+}
+
+// RowPasses reports whether a row passes every column filter of the
+// sheet's autofilter, ignoring the filter on column skip. value reads the
+// cell values as for ApplyAutoFilter.
+func RowPasses(sheet *models.Sheet, value func(row, col int) string, row, skip int) bool {
+	if sheet.Filter == nil {
+		return true
+	}
+	if value == nil {
+		value = func(row, col int) string { return sheet.CellAt(row, col).Value }
+	}
+	for _, cf := range sheet.Filter.Columns {
+		if cf.Col != skip && !MatchColumnFilter(cf, value(row, cf.Col)) {
+			return false
+		}
+	}
+	return true
+}
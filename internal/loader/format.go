@@ -0,0 +1,176 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// CopyFormat identifies a text format for copying a block of cells
+type CopyFormat int
+
+const (
+	FormatTSV CopyFormat = iota
+	FormatCSV
+	FormatMarkdown
+	FormatHTML
+	FormatJSON
+)
+
+// CopyFormats lists the available copy formats in menu order
+var CopyFormats = []CopyFormat{FormatTSV, FormatCSV, FormatMarkdown, FormatHTML, FormatJSON}
+
+// String returns the display name of the format
+func (f CopyFormat) String() string {
+	switch f {
+	case FormatCSV:
+		return "CSV"
+	case FormatMarkdown:
+		return "Markdown"
+	case FormatHTML:
+		return "HTML"
+	case FormatJSON:
+		return "JSON"
+	default:
+		return "TSV"
+	}
+}
+
+// FormatBlock renders a block of cell values as text in the given format.
+// The first row is treated as the header for Markdown, HTML and JSON.
+func FormatBlock(block [][]string, format CopyFormat) (string, error) {
+	switch format {
+	case FormatCSV:
+		return formatCSV(block)
+	case FormatMarkdown:
+		return formatMarkdown(block), nil
+	case FormatHTML:
+		return formatHTML(block), nil
+	case FormatJSON:
+		return formatJSON(block)
+	default:
+		return formatTSV(block), nil
+	}
+}
+
+// formatTSV joins cells with tabs, quoting fields that contain tabs,
+// newlines or quotes the way spreadsheets expect
+func formatTSV(block [][]string) string {
+	var b strings.Builder
+	for _, row := range block {
+		for i, value := range row {
+			if i > 0 {
+				b.WriteByte('\t')
+			}
+			if strings.ContainsAny(value, "\t\n\r\"") {
+				value = `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+			}
+			b.WriteString(value)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func formatCSV(block [][]string) (string, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(block); err != nil {
+		return "", fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func formatMarkdown(block [][]string) string {
+	if len(block) == 0 {
+		return ""
+	}
+	width := blockWidth(block)
+	escape := strings.NewReplacer("|", `\|`, "\n", "<br>", "\r", "")
+
+	var b strings.Builder
+	writeRow := func(row []string) {
+		b.WriteString("|")
+		for c := 0; c < width; c++ {
+			value := ""
+			if c < len(row) {
+				value = escape.Replace(row[c])
+			}
+			b.WriteString(" " + value + " |")
+		}
+		b.WriteString("\n")
+	}
+
+	writeRow(block[0])
+	b.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+	for _, row := range block[1:] {
+		writeRow(row)
+	}
+	return b.String()
+}
+
+func formatHTML(block [][]string) string {
+	width := blockWidth(block)
+
+	var b strings.Builder
+	b.WriteString("<table>\n")
+	for r, row := range block {
+		tag := "td"
+		if r == 0 {
+			tag = "th"
+		}
+		b.WriteString("  <tr>")
+		for c := 0; c < width; c++ {
+			value := ""
+			if c < len(row) {
+				value = strings.ReplaceAll(html.EscapeString(row[c]), "\n", "<br>")
+			}
+			b.WriteString("<" + tag + ">" + value + "</" + tag + ">")
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</table>\n")
+	return b.String()
+}
+
+func formatJSON(block [][]string) (string, error) {
+	if len(block) == 0 {
+		return "[]", nil
+	}
+
+	headers := block[0]
+	data := make([]map[string]string, 0, len(block)-1)
+	for _, row := range block[1:] {
+		record := make(map[string]string)
+		for j, value := range row {
+			headerKey := fmt.Sprintf("col_%d", j)
+			if j < len(headers) && headers[j] != "" {
+				headerKey = headers[j]
+			}
+			record[headerKey] = value
+		}
+		data = append(data, record)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(data); err != nil {
+		return "", fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func blockWidth(block [][]string) int {
+	width := 0
+	for _, row := range block {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	return width
+}
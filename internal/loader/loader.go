@@ -1,6 +1,7 @@
 package loader
 
 import (
+	"context"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
@@ -44,6 +45,10 @@ func loadExcel(filename string) ([]models.Sheet, error) {
 		return nil, fmt.Errorf("no sheets found in Excel file")
 	}
 
+	// Filters and formats are a convenience, open the workbook without them
+	// if they cannot be read
+	parts, _ := readWorksheetParts(filename)
+
 	sheets := make([]models.Sheet, 0, len(sheetList))
 
 	for _, sheetName := range sheetList {
@@ -84,6 +89,15 @@ func loadExcel(filename string) ([]models.Sheet, error) {
 			sheet.Rows = append(sheet.Rows, cellRow)
 		}
 
+		part, ok := parts[sheetName]
+		if part.filter != nil {
+			sheet.Filter = part.filter
+			ApplyAutoFilter(&sheet, nil)
+		}
+		if ok {
+			sheet.Formats, sheet.Warnings = readConditionalFormats(f, sheetName, part.formats)
+		}
+
 		sheets = append(sheets, sheet)
 	}
 
@@ -135,6 +149,36 @@ func loadCSV(filename string) ([]models.Sheet, error) {
 	return []models.Sheet{sheet}, nil
 }
 
+// ParseTSV parses tab-separated text as produced by Excel and Google Sheets
+// when copying cells. Fields containing tabs or newlines may be quoted.
+func ParseTSV(text string) ([][]string, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil, nil
+	}
+
+	// Without quoting a plain split keeps blank lines, which csv.Reader skips
+	if !strings.Contains(text, "\"") {
+		lines := strings.Split(text, "\n")
+		records := make([][]string, 0, len(lines))
+		for _, line := range lines {
+			records = append(records, strings.Split(line, "\t"))
+		}
+		return records, nil
+	}
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = '\t'
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse clipboard text: %w", err)
+	}
+	return records, nil
+}
+
 // ExportToCSV exports a sheet to CSV format
 func ExportToCSV(sheet models.Sheet, filename string) error {
 	file, err := os.Create(filename)
@@ -210,23 +254,21 @@ func ExportToJSON(sheet models.Sheet, filename string) error {
 	return nil
 }
 
-// SearchSheet searches for a term in the sheet
-func SearchSheet(sheet models.Sheet, term string) []models.Cell {
-	if term == "" {
-		return nil
-	}
-
-	term = strings.ToLower(term)
+// SearchSheet returns the cells of the sheet that match. It stops early
+// with the context's error when ctx is cancelled.
+func SearchSheet(ctx context.Context, sheet models.Sheet, matcher *Matcher) ([]models.Cell, error) {
 	results := make([]models.Cell, 0)
 
 	for _, row := range sheet.Rows {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		for _, cell := range row {
-			if strings.Contains(strings.ToLower(cell.Value), term) ||
-				strings.Contains(strings.ToLower(cell.Formula), term) {
+			if matcher.MatchCell(cell) {
 				results = append(results, cell)
 			}
 		}
 	}
 
-	return results
+	return results, nil
 }
@@ -0,0 +1,105 @@
+package loader
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/vex/pkg/models"
+)
+
+// SearchOptions controls how search terms match cell text
+type SearchOptions struct {
+	Regex         bool // Treat the term as a regular expression
+	CaseSensitive bool // Match letter case exactly
+	WholeCell     bool // Match only the entire cell text
+	WholeWord     bool // Match only at word boundaries
+	Formulas      bool // Match formula text of formula cells
+}
+
+// ParseModifiers strips vim-style modifiers from a search term and applies
+// them to opts: \c ignores case, \C matches case, \v enables regex and \V
+// disables it
+func ParseModifiers(term string, opts SearchOptions) (string, SearchOptions) {
+	var b strings.Builder
+	for i := 0; i < len(term); i++ {
+		if term[i] != '\\' || i+1 == len(term) {
+			b.WriteByte(term[i])
+			continue
+		}
+		switch term[i+1] {
+		case 'c':
+			opts.CaseSensitive = false
+		case 'C':
+			opts.CaseSensitive = true
+		case 'v':
+			opts.Regex = true
+		case 'V':
+			opts.Regex = false
+		default:
+			b.WriteString(term[i : i+2])
+		}
+		i++
+	}
+	return b.String(), opts
+}
+
+// Matcher matches and replaces a search term in cell text
+type Matcher struct {
+	re       *regexp.Regexp
+	regex    bool
+	formulas bool
+}
+
+// NewMatcher compiles a search term with the given options
+func NewMatcher(term string, opts SearchOptions) (*Matcher, error) {
+	pattern := term
+	if opts.Regex {
+		// Compile the term alone so errors quote what the user typed
+		if _, err := regexp.Compile(term); err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+	} else {
+		pattern = regexp.QuoteMeta(term)
+	}
+	switch {
+	case opts.WholeCell:
+		pattern = "^(?:" + pattern + ")$"
+	case opts.WholeWord:
+		pattern = `\b(?:` + pattern + `)\b`
+	}
+	if !opts.CaseSensitive {
+		pattern = "(?i)" + pattern
+	}
+
+	re := regexp.MustCompile(pattern)
+	return &Matcher{re: re, regex: opts.Regex, formulas: opts.Formulas}, nil
+}
+
+// Match reports whether s contains the term
+func (m *Matcher) Match(s string) bool {
+	return m.re.MatchString(s)
+}
+
+// Index returns the byte offsets of the first match in s, or nil
+func (m *Matcher) Index(s string) []int {
+	return m.re.FindStringIndex(s)
+}
+
+// MatchCell reports whether the cell value, or its formula when formulas
+// are searched, contains the term
+func (m *Matcher) MatchCell(cell models.Cell) bool {
+	if m.re.MatchString(cell.Value) {
+		return true
+	}
+	return m.formulas && cell.Formula != "" && m.re.MatchString(cell.Formula)
+}
+
+// Replace replaces every occurrence of the term in s. In regex mode the
+// replacement may reference capture groups as $1 or ${name}.
+func (m *Matcher) Replace(s, replacement string) string {
+	if m.regex {
+		return m.re.ReplaceAllString(s, replacement)
+	}
+	return m.re.ReplaceAllLiteralString(s, replacement)
+}
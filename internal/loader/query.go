@@ -0,0 +1,368 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vex/pkg/models"
+)
+
+// queryOps lists the comparison operators of a column query, longest first
+var queryOps = []string{">=", "<=", "!=", ">", "<", "=", ":"}
+
+// dateLayouts are the date formats recognised when comparing cell values
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006/01/02",
+	"01/02/2006",
+	"01-02-06",
+	"1/2/06",
+	"1/2/2006",
+	"2-Jan-2006",
+	"02-Jan-06",
+	"Jan 2, 2006",
+}
+
+// Query is a column-scoped search such as `Region:North AND Price>100`.
+// Conditions joined by AND (or just whitespace) bind tighter than OR.
+type Query struct {
+	groups [][]condition
+}
+
+// condition compares one column with a value
+type condition struct {
+	column string
+	op     string
+	value  string
+}
+
+// RowFilter is a query bound to the header row of a sheet
+type RowFilter struct {
+	groups [][]boundCondition
+	Column int // Column of the first condition
+}
+
+type boundCondition struct {
+	condition
+	col int
+}
+
+// ParseQuery parses a column query. Column names and values containing
+// spaces can be quoted: "Unit Price">=10 AND Region:"North East".
+func ParseQuery(text string) (*Query, error) {
+	words, err := splitQuoted(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+
+	q := &Query{groups: [][]condition{nil}}
+	expectTerm := true
+	for _, word := range words {
+		switch strings.ToUpper(word) {
+		case "OR", "||":
+			if expectTerm {
+				return nil, fmt.Errorf("OR without a condition before it")
+			}
+			q.groups = append(q.groups, nil)
+			expectTerm = true
+			continue
+		case "AND", "&&":
+			if expectTerm {
+				return nil, fmt.Errorf("AND without a condition before it")
+			}
+			expectTerm = true
+			continue
+		}
+
+		cond, err := parseCondition(word)
+		if err != nil {
+			return nil, err
+		}
+		last := len(q.groups) - 1
+		q.groups[last] = append(q.groups[last], cond)
+		expectTerm = false
+	}
+	if expectTerm {
+		return nil, fmt.Errorf("query ends with AND/OR")
+	}
+	return q, nil
+}
+
+// FirstColumn returns the column name of the first condition
+func (q *Query) FirstColumn() string {
+	return q.groups[0][0].column
+}
+
+// Bind resolves the query's column names against a header row
+func (q *Query) Bind(headers []string) (*RowFilter, error) {
+	filter := &RowFilter{Column: -1}
+	for _, group := range q.groups {
+		var bound []boundCondition
+		for _, cond := range group {
+			col := HeaderIndex(headers, cond.column)
+			if col < 0 {
+				return nil, fmt.Errorf("unknown column %q", cond.column)
+			}
+			if filter.Column < 0 {
+				filter.Column = col
+			}
+			bound = append(bound, boundCondition{condition: cond, col: col})
+		}
+		filter.groups = append(filter.groups, bound)
+	}
+	return filter, nil
+}
+
+// Match reports whether a row satisfies the filter
+func (f *RowFilter) Match(row []models.Cell) bool {
+	for _, group := range f.groups {
+		ok := true
+		for _, cond := range group {
+			value := ""
+			if cond.col < len(row) {
+				value = row[cond.col].Value
+			}
+			if !compareValues(value, cond.op, cond.value) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterRows returns the indices of data rows, below the header row,
+// that satisfy the filter. It stops early with the context's error when
+// ctx is cancelled.
+func FilterRows(ctx context.Context, sheet models.Sheet, header int, filter *RowFilter) ([]int, error) {
+	var rows []int
+	for i := header + 1; i < len(sheet.Rows); i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if filter.Match(sheet.Rows[i]) {
+			rows = append(rows, i)
+		}
+	}
+	return rows, nil
+}
+
+// HeaderIndex finds a column by its header name, ignoring case and
+// surrounding whitespace. It returns -1 if no header matches.
+func HeaderIndex(headers []string, name string) int {
+	name = strings.TrimSpace(name)
+	for i, header := range headers {
+		if strings.EqualFold(strings.TrimSpace(header), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// HeaderRow returns the values of the header row of a sheet
+func HeaderRow(sheet models.Sheet, header int) []string {
+	if header < 0 || header >= len(sheet.Rows) {
+		return nil
+	}
+	headers := make([]string, len(sheet.Rows[header]))
+	for i, cell := range sheet.Rows[header] {
+		headers[i] = cell.Value
+	}
+	return headers
+}
+
+// parseCondition splits a word such as Price>=100 at its operator
+func parseCondition(word string) (condition, error) {
+	inQuote := false
+	for i := 0; i < len(word); i++ {
+		if word[i] == '"' {
+			inQuote = !inQuote
+			continue
+		}
+		if inQuote {
+			continue
+		}
+		for _, op := range queryOps {
+			if strings.HasPrefix(word[i:], op) {
+				column := unquote(word[:i])
+				if column == "" {
+					return condition{}, fmt.Errorf("missing column before %q", op)
+				}
+				return condition{column: column, op: op, value: unquote(word[i+len(op):])}, nil
+			}
+		}
+	}
+	return condition{}, fmt.Errorf("%q is not a column condition", word)
+}
+
+// compareValues applies an operator, comparing as numbers or dates when
+// both sides parse as such and as case-insensitive text otherwise
+func compareValues(cell, op, want string) bool {
+	if op == ":" {
+		return strings.Contains(strings.ToLower(cell), strings.ToLower(want))
+	}
+
+	var cmp int
+	if a, ok := ParseNumber(cell); ok {
+		b, ok := ParseNumber(want)
+		if !ok {
+			return op == "!="
+		}
+		cmp = compareFloat(a, b)
+	} else if a, ok := ParseDate(cell); ok {
+		b, ok := ParseDate(want)
+		if !ok {
+			return op == "!="
+		}
+		cmp = a.Compare(b)
+	} else {
+		cmp = strings.Compare(strings.ToLower(cell), strings.ToLower(want))
+	}
+
+	switch op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+	return false
+}
+
+// ParseNumber parses a cell value as a number, accepting thousands
+// separators in groups of three before the decimal point, a currency symbol
+// before or after the number and a trailing percent sign. Text such as
+// "inf" or "NaN" is not a number.
+func ParseNumber(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	percent := strings.HasSuffix(s, "%")
+	s = strings.TrimSuffix(s, "%")
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+	for _, symbol := range []string{"$", "€", "£", "¥"} {
+		if strings.HasPrefix(s, symbol) {
+			s = strings.TrimPrefix(s, symbol)
+			break
+		}
+		if strings.HasSuffix(s, symbol) {
+			s = strings.TrimSuffix(s, symbol)
+			break
+		}
+	}
+	s, ok := stripThousands(strings.TrimSpace(s))
+	if !ok {
+		return 0, false
+	}
+
+	f, err := strconv.ParseFloat(sign+s, 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return 0, false
+	}
+	if percent {
+		f /= 100
+	}
+	return f, true
+}
+
+// stripThousands removes the thousands separators from the integer part of
+// a number. It reports false when a comma appears elsewhere or the digits
+// between commas are not groups of three.
+func stripThousands(s string) (string, bool) {
+	if !strings.Contains(s, ",") {
+		return s, true
+	}
+	whole, rest := s, ""
+	if i := strings.IndexAny(s, ".eE"); i >= 0 {
+		whole, rest = s[:i], s[i:]
+	}
+	if strings.Contains(rest, ",") {
+		return "", false
+	}
+	groups := strings.Split(whole, ",")
+	for i, g := range groups {
+		if (i == 0 && len(g) > 3) || (i > 0 && len(g) != 3) || strings.Trim(g, "0123456789") != "" || g == "" {
+			return "", false
+		}
+	}
+	return strings.Join(groups, "") + rest, true
+}
+
+// ParseDate parses a cell value in one of the common date layouts
+func ParseDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// splitQuoted splits text on whitespace outside double quotes
+func splitQuoted(text string) ([]string, error) {
+	var words []string
+	var b strings.Builder
+	inQuote := false
+	for _, r := range text {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			b.WriteRune(r)
+		case !inQuote && (r == ' ' || r == '\t'):
+			if b.Len() > 0 {
+				words = append(words, b.String())
+				b.Reset()
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if b.Len() > 0 {
+		words = append(words, b.String())
+	}
+	return words, nil
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
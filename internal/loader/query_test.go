@@ -0,0 +1,45 @@
+package loader
+
+import "testing"
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{"42", 42, true},
+		{" -3.5 ", -3.5, true},
+		{"+7", 7, true},
+		{"1e3", 1000, true},
+		{"1,234", 1234, true},
+		{"1,234,567.89", 1234567.89, true},
+		{"-1,000", -1000, true},
+		{"$1,200", 1200, true},
+		{"-$5", -5, true},
+		{"12 €", 12, true},
+		{"£3.50", 3.5, true},
+		{"45%", 0.45, true},
+		{"-12.5%", -0.125, true},
+		{"", 0, false},
+		{"abc", 0, false},
+		{"1,23", 0, false},
+		{"12,34,567", 0, false},
+		{"1234,567", 0, false},
+		{",123", 0, false},
+		{"1,234.5,6", 0, false},
+		{"1.5e3,000", 0, false},
+		{"1$2", 0, false},
+		{"$$5", 0, false},
+		{"5$€", 0, false},
+		{"inf", 0, false},
+		{"NaN", 0, false},
+		{"1e999", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseNumber(tt.in)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("ParseNumber(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package loader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/vex/pkg/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// SaveFile writes modified sheets back to the file they were loaded from
+func SaveFile(filename string, sheets []models.Sheet) error {
+	ext := strings.ToLower(filepath.Ext(filename))
+
+	switch ext {
+	case ".xlsx", ".xlsm":
+		return saveExcel(filename, sheets)
+	case ".csv":
+		if len(sheets) == 0 {
+			return fmt.Errorf("nothing to save")
+		}
+		return ExportToCSV(sheets[0], filename)
+	default:
+		return fmt.Errorf("saving %s files is not supported", ext)
+	}
+}
+
+// saveExcel writes changed cells of dirty sheets into the existing workbook,
+// leaving styles, untouched cells and other sheets as they are
+func saveExcel(filename string, sheets []models.Sheet) error {
+	f, err := excelize.OpenFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open Excel file: %w", err)
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to close file: %v\n", closeErr)
+		}
+	}()
+
+	for _, sheet := range sheets {
+		if !sheet.Dirty || sheet.Virtual {
+			continue
+		}
+		if idx, err := f.GetSheetIndex(sheet.Name); err != nil || idx < 0 {
+			return fmt.Errorf("sheet '%s' not found in %s", sheet.Name, filepath.Base(filename))
+		}
+		if err := replayEdits(f, sheet); err != nil {
+			return err
+		}
+		if err := writeSheetCells(f, sheet); err != nil {
+			return err
+		}
+	}
+
+	if err := f.Save(); err != nil {
+		return fmt.Errorf("failed to save Excel file: %w", err)
+	}
+	return nil
+}
+
+// replayEdits applies recorded row and column changes to the workbook so
+// that styles and formula references move along with the data
+func replayEdits(f *excelize.File, sheet models.Sheet) error {
+	for _, edit := range sheet.Edits {
+		var err error
+		switch edit.Kind {
+		case models.EditInsertRows:
+			err = f.InsertRows(sheet.Name, edit.Index+1, edit.Count)
+		case models.EditDeleteRows:
+			for i := 0; i < edit.Count && err == nil; i++ {
+				err = f.RemoveRow(sheet.Name, edit.Index+1)
+			}
+		case models.EditInsertCols:
+			var col string
+			if col, err = excelize.ColumnNumberToName(edit.Index + 1); err == nil {
+				err = f.InsertCols(sheet.Name, col, edit.Count)
+			}
+		case models.EditDeleteCols:
+			var col string
+			if col, err = excelize.ColumnNumberToName(edit.Index + 1); err == nil {
+				for i := 0; i < edit.Count && err == nil; i++ {
+					err = f.RemoveCol(sheet.Name, col)
+				}
+			}
+		case models.EditCopyRow:
+			err = f.DuplicateRowTo(sheet.Name, edit.Index+1, edit.To+1)
+		case models.EditPermuteRows:
+			err = permuteRowStyles(f, sheet.Name, edit.Index, edit.Order, sheet.MaxCols)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update rows/columns in '%s': %w", sheet.Name, err)
+		}
+	}
+	return nil
+}
+
+// permuteRowStyles moves the heights and cell styles of a block of rows
+// into a new order in place. The cell values follow in writeSheetCells.
+func permuteRowStyles(f *excelize.File, sheet string, from int, order []int, cols int) error {
+	type rowStyle struct {
+		height float64
+		styles []int
+	}
+	saved := make([]rowStyle, len(order))
+	for i := range order {
+		row := from + i + 1
+		height, err := f.GetRowHeight(sheet, row)
+		if err != nil {
+			return err
+		}
+		saved[i] = rowStyle{height: height, styles: make([]int, cols)}
+		for col := range saved[i].styles {
+			cell, err := excelize.CoordinatesToCellName(col+1, row)
+			if err != nil {
+				return err
+			}
+			if saved[i].styles[col], err = f.GetCellStyle(sheet, cell); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, src := range order {
+		row := from + i + 1
+		if saved[src].height != saved[i].height {
+			if err := f.SetRowHeight(sheet, row, saved[src].height); err != nil {
+				return err
+			}
+		}
+		for col, style := range saved[src].styles {
+			if style == saved[i].styles[col] {
+				continue
+			}
+			cell, err := excelize.CoordinatesToCellName(col+1, row)
+			if err != nil {
+				return err
+			}
+			if err := f.SetCellStyle(sheet, cell, cell, style); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeSheetCells updates every cell whose value or formula differs from the workbook
+func writeSheetCells(f *excelize.File, sheet models.Sheet) error {
+	for rowIdx, row := range sheet.Rows {
+		for colIdx, cell := range row {
+			cellRef, err := excelize.CoordinatesToCellName(colIdx+1, rowIdx+1)
+			if err != nil {
+				return err
+			}
+
+			formula, _ := f.GetCellFormula(sheet.Name, cellRef)
+			if cell.Formula != "" {
+				if cell.Formula != formula {
+					if err := f.SetCellFormula(sheet.Name, cellRef, cell.Formula); err != nil {
+						return fmt.Errorf("failed to write %s!%s: %w", sheet.Name, cellRef, err)
+					}
+				}
+				continue
+			}
+
+			value, _ := f.GetCellValue(sheet.Name, cellRef)
+			if formula == "" && value == cell.Value {
+				continue
+			}
+			if formula != "" {
+				if err := f.SetCellFormula(sheet.Name, cellRef, ""); err != nil {
+					return fmt.Errorf("failed to clear formula in %s!%s: %w", sheet.Name, cellRef, err)
+				}
+			}
+			if err := f.SetCellValue(sheet.Name, cellRef, typedValue(cell.Value)); err != nil {
+				return fmt.Errorf("failed to write %s!%s: %w", sheet.Name, cellRef, err)
+			}
+		}
+	}
+	return nil
+}
+
+// typedValue converts numeric text to a number so Excel does not store it
+// as text. Values that would not round-trip (such as "007") stay strings.
+func typedValue(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	if n, err := strconv.ParseFloat(value, 64); err == nil && strconv.FormatFloat(n, 'f', -1, 64) == value {
+		return n
+	}
+	return value
+}
@@ -0,0 +1,242 @@
+// Package outliers finds cells whose values stand out from the rest of
+// their column.
+package outliers
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vex/internal/loader"
+	"github.com/vex/internal/profile"
+	"github.com/vex/internal/stats"
+	"github.com/vex/pkg/models"
+)
+
+const (
+	// minNumbers is the number of numeric values a column needs before
+	// any of them counts as an outlier
+	minNumbers = 4
+	// minPatternValues is the number of values a column needs before a
+	// dominant pattern is looked for
+	minPatternValues = 5
+	// patternShare is the share of values a pattern needs to be dominant
+	patternShare = 0.8
+)
+
+// Method is the test numeric values are checked with
+type Method int
+
+const (
+	MethodStdDev Method = iota // More than Threshold standard deviations from the mean
+	MethodIQR                  // Beyond Threshold interquartile ranges outside the quartiles
+)
+
+func (m Method) String() string {
+	if m == MethodIQR {
+		return "IQR fences"
+	}
+	return "standard deviations"
+}
+
+// DefaultThreshold returns the usual threshold of a method: 3 standard
+// deviations or Tukey's fences at 1.5 IQR
+func (m Method) DefaultThreshold() float64 {
+	if m == MethodIQR {
+		return 1.5
+	}
+	return 3
+}
+
+// Options selects how outliers are found
+type Options struct {
+	Method    Method
+	Threshold float64
+	Patterns  bool // Also flag text breaking the dominant pattern of its column
+	HeaderRow int  // Rows up to and including it are never checked
+}
+
+// Kind is why a cell was flagged
+type Kind int
+
+const (
+	KindValue   Kind = iota // A numeric outlier
+	KindPattern             // A value breaking the column's pattern
+)
+
+// Cell is a flagged cell with the reason it stands out
+type Cell struct {
+	Row, Col int
+	Kind     Kind
+	Reason   string
+}
+
+// Find returns the flagged cells of a sheet in row order. Rows skip
+// excludes are neither flagged nor counted in the column statistics.
+func Find(sheet *models.Sheet, opts Options, skip func(row int) bool) []Cell {
+	var rows []int
+	for row := opts.HeaderRow + 1; row < sheet.MaxRows; row++ {
+		if skip == nil || !skip(row) {
+			rows = append(rows, row)
+		}
+	}
+
+	var cells []Cell
+	for col := 0; col < sheet.MaxCols; col++ {
+		values := make([]string, len(rows))
+		for i, row := range rows {
+			values[i] = strings.TrimSpace(sheet.CellAt(row, col).Value)
+		}
+		if numeric(values) {
+			cells = append(cells, numericOutliers(values, rows, col, opts)...)
+		} else if opts.Patterns {
+			cells = append(cells, patternBreaks(values, rows, col)...)
+		}
+	}
+
+	sort.SliceStable(cells, func(i, j int) bool {
+		if cells[i].Row != cells[j].Row {
+			return cells[i].Row < cells[j].Row
+		}
+		return cells[i].Col < cells[j].Col
+	})
+	return cells
+}
+
+// numeric reports whether most non-blank values of a column are numbers
+func numeric(values []string) bool {
+	count, nums := 0, 0
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		count++
+		if _, ok := loader.ParseNumber(v); ok {
+			nums++
+		}
+	}
+	return nums >= minNumbers && nums*2 > count
+}
+
+// numericOutliers flags the numbers of a column outside the bounds of the
+// method
+func numericOutliers(values []string, rows []int, col int, opts Options) []Cell {
+	var nums []float64
+	for _, v := range values {
+		if n, ok := loader.ParseNumber(v); ok {
+			nums = append(nums, n)
+		}
+	}
+
+	var test func(n float64) (string, bool)
+	switch opts.Method {
+	case MethodIQR:
+		sorted := append([]float64(nil), nums...)
+		sort.Float64s(sorted)
+		q1, q3 := stats.Percentile(sorted, 25), stats.Percentile(sorted, 75)
+		lo, hi := q1-opts.Threshold*(q3-q1), q3+opts.Threshold*(q3-q1)
+		test = func(n float64) (string, bool) {
+			switch {
+			case n < lo:
+				return "below the lower fence " + formatNumber(lo), true
+			case n > hi:
+				return "above the upper fence " + formatNumber(hi), true
+			}
+			return "", false
+		}
+	default:
+		mean, sd := meanStdDev(nums)
+		if sd == 0 {
+			return nil
+		}
+		test = func(n float64) (string, bool) {
+			z := (n - mean) / sd
+			if math.Abs(z) <= opts.Threshold {
+				return "", false
+			}
+			side := "above"
+			if z < 0 {
+				side = "below"
+			}
+			return fmt.Sprintf("%.1fσ %s the mean %s", math.Abs(z), side, formatNumber(mean)), true
+		}
+	}
+
+	var cells []Cell
+	for i, v := range values {
+		n, ok := loader.ParseNumber(v)
+		if !ok {
+			continue
+		}
+		if reason, out := test(n); out {
+			cells = append(cells, Cell{Row: rows[i], Col: col, Kind: KindValue, Reason: reason})
+		}
+	}
+	return cells
+}
+
+// patternBreaks flags the values of a column whose pattern differs from the
+// one most values share
+func patternBreaks(values []string, rows []int, col int) []Cell {
+	counts := make(map[string]int)
+	count := 0
+	for _, v := range values {
+		if v != "" {
+			counts[profile.Pattern(v)]++
+			count++
+		}
+	}
+	if count < minPatternValues {
+		return nil
+	}
+	dominant, best := "", 0
+	for p, n := range counts {
+		if n > best || (n == best && p < dominant) {
+			dominant, best = p, n
+		}
+	}
+	if best == count || float64(best) < patternShare*float64(count) {
+		return nil
+	}
+
+	var cells []Cell
+	for i, v := range values {
+		if v == "" {
+			continue
+		}
+		if p := profile.Pattern(v); p != dominant {
+			cells = append(cells, Cell{
+				Row:    rows[i],
+				Col:    col,
+				Kind:   KindPattern,
+				Reason: fmt.Sprintf("pattern %s, usually %s", p, dominant),
+			})
+		}
+	}
+	return cells
+}
+
+// meanStdDev returns the mean and sample standard deviation of numbers
+func meanStdDev(nums []float64) (float64, float64) {
+	var sum float64
+	for _, n := range nums {
+		sum += n
+	}
+	mean := sum / float64(len(nums))
+	if len(nums) < 2 {
+		return mean, 0
+	}
+	var sq float64
+	for _, n := range nums {
+		sq += (n - mean) * (n - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(nums)-1))
+}
+
+// formatNumber formats a bound with at most four decimals
+func formatNumber(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 4, 64)
+	return strings.TrimRight(strings.TrimRight(s, "0"), ".")
+}
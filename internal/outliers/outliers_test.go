@@ -0,0 +1,59 @@
+package outliers
+
+import (
+	"testing"
+
+	"github.com/vex/pkg/models"
+)
+
+func TestFind(t *testing.T) {
+	sheet := models.NewSheet("S", [][]string{
+		{"Amount", "Code", "Note"},
+		{"10", "AB-101", "ok"},
+		{"12", "AB-102", "fine"},
+		{"11", "AB-103", "good"},
+		{"13", "AB-104", "ok"},
+		{"9", "AB-105", "meh"},
+		{"10", "AB-106", "ok"},
+		{"11", "ab_107", "ok"},
+		{"12", "AB-108", "ok"},
+		{"500", "AB-109", "ok"},
+	})
+	type flag struct {
+		row, col int
+		kind     Kind
+	}
+	tests := []struct {
+		name string
+		opts Options
+		skip func(row int) bool
+		want []flag
+	}{
+		{"standard deviations", Options{Method: MethodStdDev, Threshold: 2}, nil, []flag{{9, 0, KindValue}}},
+		{"loose threshold", Options{Method: MethodStdDev, Threshold: 3}, nil, nil},
+		{"IQR fences", Options{Method: MethodIQR, Threshold: 1.5}, nil, []flag{{9, 0, KindValue}}},
+		{"patterns", Options{Method: MethodIQR, Threshold: 1.5, Patterns: true}, nil, []flag{{7, 1, KindPattern}, {9, 0, KindValue}}},
+		{"skipped rows", Options{Method: MethodIQR, Threshold: 1.5, Patterns: true}, func(row int) bool { return row == 7 || row == 9 }, nil},
+		{"header row", Options{Method: MethodIQR, Threshold: 1.5, HeaderRow: 6}, nil, nil},
+	}
+	for _, tt := range tests {
+		cells := Find(&sheet, tt.opts, tt.skip)
+		var got []flag
+		for _, c := range cells {
+			got = append(got, flag{c.Row, c.Col, c.Kind})
+			if c.Reason == "" {
+				t.Errorf("%s: cell %d,%d has no reason", tt.name, c.Row, c.Col)
+			}
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: Find = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: Find = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,287 @@
+// Package pivot groups the rows of a sheet into a pivot table.
+package pivot
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vex/internal/loader"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+// Aggregate is how the values of a pivot cell are combined
+type Aggregate int
+
+const (
+	Sum Aggregate = iota
+	Count
+	Average
+	Min
+	Max
+	Distinct
+)
+
+// Aggregates lists every aggregate in the order they are offered
+var Aggregates = []Aggregate{Sum, Count, Average, Min, Max, Distinct}
+
+func (a Aggregate) String() string {
+	switch a {
+	case Count:
+		return "Count"
+	case Average:
+		return "Average"
+	case Min:
+		return "Min"
+	case Max:
+		return "Max"
+	case Distinct:
+		return "Distinct"
+	}
+	return "Sum"
+}
+
+// Spec describes a pivot table. Fields are column indexes of the source
+// sheet, whose HeaderRow names them.
+type Spec struct {
+	Rows      []int
+	Cols      []int
+	Value     int
+	Agg       Aggregate
+	HeaderRow int
+}
+
+// keySep joins the values of several fields into one map key
+const keySep = "\x00"
+
+// acc accumulates the values of one pivot cell
+type acc struct {
+	count    int // Non-blank values
+	numeric  int
+	sum      float64
+	min, max float64
+	distinct map[string]bool
+}
+
+func (a *acc) add(v string) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return
+	}
+	a.count++
+	if a.distinct == nil {
+		a.distinct = make(map[string]bool)
+	}
+	a.distinct[v] = true
+	n, ok := loader.ParseNumber(v)
+	if !ok {
+		return
+	}
+	if a.numeric == 0 || n < a.min {
+		a.min = n
+	}
+	if a.numeric == 0 || n > a.max {
+		a.max = n
+	}
+	a.numeric++
+	a.sum += n
+}
+
+// value formats the aggregate of the cell, blank when there is nothing to
+// aggregate
+func (a *acc) value(agg Aggregate) string {
+	if a == nil {
+		return ""
+	}
+	switch agg {
+	case Count:
+		return strconv.Itoa(a.count)
+	case Distinct:
+		return strconv.Itoa(len(a.distinct))
+	}
+	if a.numeric == 0 {
+		return ""
+	}
+	switch agg {
+	case Average:
+		return formatNumber(a.sum / float64(a.numeric))
+	case Min:
+		return formatNumber(a.min)
+	case Max:
+		return formatNumber(a.max)
+	}
+	return formatNumber(a.sum)
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// FieldName returns the header of a source column, or its letter
+func FieldName(sheet *models.Sheet, header, col int) string {
+	if name := strings.TrimSpace(sheet.CellAt(header, col).Value); name != "" {
+		return name
+	}
+	return ui.ColIndexToLetter(col)
+}
+
+// Build computes the pivot table of the rows below the header that skip
+// does not exclude. The result has one row per combination of row field
+// values with subtotals after each group of the outer fields, one column
+// per combination of column field values, and grand totals.
+func Build(sheet *models.Sheet, spec Spec, skip func(row int) bool) ([][]string, error) {
+	if len(spec.Rows) == 0 {
+		return nil, fmt.Errorf("pick at least one row field")
+	}
+
+	// cells[rowKey][colKey] for every prefix of the row fields, the empty
+	// prefix being the grand total; colKey "" is the total across columns
+	cells := make(map[string]map[string]*acc)
+	rowKeys := make(map[string][]string)
+	colKeys := make(map[string][]string)
+
+	add := func(rk, ck, v string) {
+		row := cells[rk]
+		if row == nil {
+			row = make(map[string]*acc)
+			cells[rk] = row
+		}
+		a := row[ck]
+		if a == nil {
+			a = &acc{}
+			row[ck] = a
+		}
+		a.add(v)
+	}
+
+	for r := spec.HeaderRow + 1; r < sheet.MaxRows; r++ {
+		if skip != nil && skip(r) {
+			continue
+		}
+		rvals := fieldValues(sheet, r, spec.Rows)
+		cvals := fieldValues(sheet, r, spec.Cols)
+		ck := prefixKey(cvals, len(cvals))
+		rowKeys[strings.Join(rvals, keySep)] = rvals
+		if len(spec.Cols) > 0 {
+			colKeys[ck] = cvals
+		}
+
+		v := sheet.CellAt(r, spec.Value).Value
+		for l := 0; l <= len(rvals); l++ {
+			rk := prefixKey(rvals, l)
+			if len(spec.Cols) > 0 {
+				add(rk, ck, v)
+			}
+			add(rk, "", v)
+		}
+	}
+
+	rows := sortedKeys(rowKeys)
+	cols := sortedKeys(colKeys)
+	colKeyOf := make([]string, len(cols))
+	for i, c := range cols {
+		colKeyOf[i] = prefixKey(c, len(c))
+	}
+
+	// Header
+	header := make([]string, 0, len(spec.Rows)+len(cols)+1)
+	for _, f := range spec.Rows {
+		header = append(header, FieldName(sheet, spec.HeaderRow, f))
+	}
+	for _, c := range cols {
+		header = append(header, labelOf(c))
+	}
+	if len(cols) > 0 {
+		header = append(header, "Grand Total")
+	} else {
+		header = append(header, fmt.Sprintf("%s of %s", spec.Agg, FieldName(sheet, spec.HeaderRow, spec.Value)))
+	}
+	out := [][]string{header}
+
+	line := func(labels []string, rk string) []string {
+		row := make([]string, 0, len(header))
+		for _, l := range labels {
+			row = append(row, labelOf([]string{l}))
+		}
+		for len(row) < len(spec.Rows) {
+			row = append(row, "")
+		}
+		for _, ck := range colKeyOf {
+			row = append(row, cells[rk][ck].value(spec.Agg))
+		}
+		return append(row, cells[rk][""].value(spec.Agg))
+	}
+	subtotal := func(key []string, level int) []string {
+		labels := append([]string(nil), key[:level]...)
+		labels[level-1] = labelOf(key[level-1:level]) + " Total"
+		return line(labels, prefixKey(key, level))
+	}
+
+	for i, key := range rows {
+		if i > 0 {
+			prev := rows[i-1]
+			d := 0
+			for d < len(key) && key[d] == prev[d] {
+				d++
+			}
+			for level := len(key) - 1; level > d; level-- {
+				out = append(out, subtotal(prev, level))
+			}
+		}
+		out = append(out, line(key, prefixKey(key, len(key))))
+	}
+	if len(rows) > 0 {
+		last := rows[len(rows)-1]
+		for level := len(last) - 1; level > 0; level-- {
+			out = append(out, subtotal(last, level))
+		}
+	}
+	out = append(out, line([]string{"Grand Total"}, prefixKey(nil, 0)))
+	return out, nil
+}
+
+// prefixKey is the key of the first n field values. The length is part
+// of the key so a blank value is not mistaken for a shorter prefix.
+func prefixKey(values []string, n int) string {
+	return strconv.Itoa(n) + keySep + strings.Join(values[:n], keySep)
+}
+
+// fieldValues returns the trimmed values of the given columns of a row
+func fieldValues(sheet *models.Sheet, row int, cols []int) []string {
+	values := make([]string, len(cols))
+	for i, c := range cols {
+		values[i] = strings.TrimSpace(sheet.CellAt(row, c).Value)
+	}
+	return values
+}
+
+// sortedKeys orders field value combinations field by field
+func sortedKeys(keys map[string][]string) [][]string {
+	sorted := make([][]string, 0, len(keys))
+	for _, k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		for f := range sorted[i] {
+			if c := loader.CompareValues(sorted[i][f], sorted[j][f]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return sorted
+}
+
+// labelOf joins field values for display, naming blanks like Excel does
+func labelOf(values []string) string {
+	labels := make([]string, len(values))
+	for i, v := range values {
+		if v == "" {
+			v = "(blank)"
+		}
+		labels[i] = v
+	}
+	return strings.Join(labels, " / ")
+}
@@ -0,0 +1,306 @@
+// Package profile describes the type, shape and quality of the values in
+// each column of a sheet.
+package profile
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/vex/internal/loader"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+const (
+	// topPatterns is the number of most common patterns kept per column
+	topPatterns = 3
+	// maxPatternLen is the length from which patterns are cut short
+	maxPatternLen = 24
+)
+
+// Type is the kind of value a cell holds
+type Type string
+
+const (
+	TypeEmpty  Type = "empty"
+	TypeNumber Type = "number"
+	TypeDate   Type = "date"
+	TypeBool   Type = "boolean"
+	TypeText   Type = "text"
+)
+
+// Flag names a suspicious property of a column
+type Flag string
+
+const (
+	FlagMixedTypes    Flag = "mixed types"
+	FlagWhitespace    Flag = "leading/trailing whitespace"
+	FlagNumbersAsText Flag = "numbers stored as text"
+)
+
+// Issue is a flag raised on a column with the number of values causing it
+// and the first of them
+type Issue struct {
+	Flag    Flag   `json:"flag"`
+	Count   int    `json:"count"`
+	Example string `json:"example"`
+	Row     int    `json:"-"` // Row of the first offending value
+}
+
+// PatternCount is a value shape and the number of values with it
+type PatternCount struct {
+	Pattern string `json:"pattern"`
+	Count   int    `json:"count"`
+}
+
+// Range is the smallest and largest number of a column
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// Column is the profile of one column. Values are compared after trimming
+// surrounding whitespace.
+type Column struct {
+	Col      int            `json:"-"`
+	Letter   string         `json:"column"`
+	Name     string         `json:"name"`
+	Type     Type           `json:"type"`
+	Types    map[Type]int   `json:"types,omitempty"` // Values of each type when there are several
+	Count    int            `json:"count"`           // Non-blank values
+	Blanks   int            `json:"blanks"`
+	NullRate float64        `json:"null_rate"`
+	Distinct int            `json:"distinct"`
+	Patterns []PatternCount `json:"patterns,omitempty"` // Most common patterns, most common first
+	Shapes   int            `json:"pattern_count"`      // Number of distinct patterns
+	MinLen   int            `json:"min_length"`
+	MaxLen   int            `json:"max_length"`
+	Min      string         `json:"min,omitempty"` // Smallest and largest value in sort order
+	Max      string         `json:"max,omitempty"`
+	Range    *Range         `json:"range,omitempty"`
+	Issues   []Issue        `json:"issues,omitempty"`
+}
+
+// Report is the profile of a sheet
+type Report struct {
+	Sheet   string   `json:"sheet"`
+	Rows    int      `json:"rows"` // Rows profiled below the header
+	Columns []Column `json:"columns"`
+}
+
+// Flagged returns the columns with at least one issue
+func (r *Report) Flagged() int {
+	n := 0
+	for _, c := range r.Columns {
+		if len(c.Issues) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// Profile profiles every column of a sheet from the rows below headerRow,
+// naming columns after the header. Rows skip excludes are left out.
+func Profile(sheet *models.Sheet, headerRow int, skip func(row int) bool) Report {
+	r := Report{Sheet: sheet.Name}
+	var rows []int
+	for row := headerRow + 1; row < sheet.MaxRows; row++ {
+		if skip == nil || !skip(row) {
+			rows = append(rows, row)
+		}
+	}
+	r.Rows = len(rows)
+
+	r.Columns = make([]Column, sheet.MaxCols)
+	for col := range r.Columns {
+		r.Columns[col] = profileColumn(sheet, col, headerRow, rows)
+	}
+	return r
+}
+
+// profileColumn profiles the values of a column in the given rows
+func profileColumn(sheet *models.Sheet, col, headerRow int, rows []int) Column {
+	c := Column{
+		Col:    col,
+		Letter: ui.ColIndexToLetter(col),
+		Types:  make(map[Type]int),
+	}
+	if headerRow >= 0 {
+		c.Name = strings.TrimSpace(sheet.CellAt(headerRow, col).Value)
+	}
+
+	distinct := make(map[string]bool)
+	patterns := make(map[string]int)
+	issues := make(map[Flag]*Issue)
+	raise := func(flag Flag, row int, value string) {
+		if is, ok := issues[flag]; ok {
+			is.Count++
+			return
+		}
+		issues[flag] = &Issue{Flag: flag, Count: 1, Example: value, Row: row}
+	}
+
+	for _, row := range rows {
+		raw := sheet.CellAt(row, col).Value
+		v := strings.TrimSpace(raw)
+		if v == "" {
+			c.Blanks++
+			continue
+		}
+		if v != raw {
+			raise(FlagWhitespace, row, raw)
+		}
+		if numberAsText(v) {
+			raise(FlagNumbersAsText, row, v)
+		}
+
+		c.Count++
+		distinct[v] = true
+		patterns[Pattern(v)]++
+
+		n := utf8.RuneCountInString(v)
+		if c.Count == 1 || n < c.MinLen {
+			c.MinLen = n
+		}
+		c.MaxLen = max(c.MaxLen, n)
+		if c.Min == "" || loader.CompareValues(v, c.Min) < 0 {
+			c.Min = v
+		}
+		if c.Max == "" || loader.CompareValues(v, c.Max) > 0 {
+			c.Max = v
+		}
+
+		t := valueType(v)
+		c.Types[t]++
+		if t == TypeNumber {
+			f, _ := loader.ParseNumber(strings.TrimPrefix(v, "'"))
+			if c.Range == nil {
+				c.Range = &Range{Min: f, Max: f}
+			}
+			c.Range.Min = min(c.Range.Min, f)
+			c.Range.Max = max(c.Range.Max, f)
+		}
+	}
+
+	if total := c.Count + c.Blanks; total > 0 {
+		c.NullRate = float64(c.Blanks) / float64(total)
+	}
+	c.Distinct = len(distinct)
+	c.Shapes = len(patterns)
+	c.Patterns = topCounts(patterns, topPatterns)
+	c.Type = dominant(c.Types)
+
+	if len(c.Types) > 1 {
+		// The first value of a minority type is the example
+		for _, row := range rows {
+			v := strings.TrimSpace(sheet.CellAt(row, col).Value)
+			if v != "" && valueType(v) != c.Type {
+				issues[FlagMixedTypes] = &Issue{Flag: FlagMixedTypes, Count: c.Count - c.Types[c.Type], Example: v, Row: row}
+				break
+			}
+		}
+	} else {
+		c.Types = nil
+	}
+
+	for _, flag := range []Flag{FlagMixedTypes, FlagWhitespace, FlagNumbersAsText} {
+		if is, ok := issues[flag]; ok {
+			c.Issues = append(c.Issues, *is)
+		}
+	}
+	return c
+}
+
+// TypeOf infers the type of a trimmed, non-blank value
+func TypeOf(v string) Type {
+	if _, ok := loader.ParseNumber(v); ok {
+		return TypeNumber
+	}
+	if _, ok := loader.ParseDate(v); ok {
+		return TypeDate
+	}
+	switch strings.ToLower(v) {
+	case "true", "false":
+		return TypeBool
+	}
+	return TypeText
+}
+
+// valueType is the type of a value within a column, where a number stored
+// as text counts as a number
+func valueType(v string) Type {
+	if numberAsText(v) {
+		return TypeNumber
+	}
+	return TypeOf(v)
+}
+
+// Pattern returns the shape of a value: letters become A, digits 9 and
+// other characters stay as they are, so "ABC-1234" reads "AAA-9999". Long
+// values are cut short with an ellipsis.
+func Pattern(v string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range v {
+		if n == maxPatternLen {
+			b.WriteRune('…')
+			break
+		}
+		switch {
+		case unicode.IsLetter(r):
+			b.WriteByte('A')
+		case unicode.IsDigit(r):
+			b.WriteByte('9')
+		default:
+			b.WriteRune(r)
+		}
+		n++
+	}
+	return b.String()
+}
+
+// numberAsText reports whether a value is a number written the way only a
+// text cell keeps it: with a leading apostrophe or leading zeros
+func numberAsText(v string) bool {
+	if rest, ok := strings.CutPrefix(v, "'"); ok {
+		_, isNum := loader.ParseNumber(rest)
+		return isNum
+	}
+	if len(v) < 2 || v[0] != '0' || v[1] < '0' || v[1] > '9' {
+		return false
+	}
+	_, isNum := loader.ParseNumber(v)
+	return isNum
+}
+
+// dominant returns the most common type, preferring the narrower type on
+// ties
+func dominant(types map[Type]int) Type {
+	best := TypeEmpty
+	for _, t := range []Type{TypeNumber, TypeDate, TypeBool, TypeText} {
+		if types[t] > types[best] {
+			best = t
+		}
+	}
+	return best
+}
+
+// topCounts returns the n most frequent patterns, ties in pattern order
+func topCounts(counts map[string]int, n int) []PatternCount {
+	top := make([]PatternCount, 0, len(counts))
+	for p, c := range counts {
+		top = append(top, PatternCount{Pattern: p, Count: c})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Pattern < top[j].Pattern
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
@@ -0,0 +1,113 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteJSON writes reports as an indented JSON array
+func WriteJSON(w io.Writer, reports []Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(reports)
+}
+
+// WriteMarkdown writes each report as a heading and a table of its
+// columns, followed by a list of the issues found
+func WriteMarkdown(w io.Writer, reports []Report) error {
+	var b strings.Builder
+	for i, r := range reports {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "## %s\n\n", r.Sheet)
+		fmt.Fprintf(&b, "%d rows, %d columns, %d flagged\n\n", r.Rows, len(r.Columns), r.Flagged())
+
+		b.WriteString("| Column | Name | Type | Nulls | Distinct | Patterns | Length | Range | Flags |\n")
+		b.WriteString("|---|---|---|---:|---:|---|---|---|---|\n")
+		for _, c := range r.Columns {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %d | %s | %s | %s | %s |\n",
+				c.Letter, markdownCell(c.Name), c.Type, FormatRate(c.NullRate), c.Distinct,
+				markdownCell(c.PatternSummary()), c.LengthRange(), markdownCell(c.ValueRange()), markdownCell(c.FlagSummary()))
+		}
+
+		var issues []string
+		for _, c := range r.Columns {
+			for _, is := range c.Issues {
+				issues = append(issues, fmt.Sprintf("- **%s** (%s): %s in %d value(s), first at row %d: `%s`",
+					c.Letter, markdownCell(c.Name), is.Flag, is.Count, is.Row+1, markdownCell(strings.ReplaceAll(is.Example, "`", "'"))))
+			}
+		}
+		if len(issues) > 0 {
+			b.WriteString("\n" + strings.Join(issues, "\n") + "\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// PatternSummary lists the most common patterns with their share of the
+// values
+func (c *Column) PatternSummary() string {
+	parts := make([]string, len(c.Patterns))
+	for i, p := range c.Patterns {
+		parts[i] = fmt.Sprintf("%s %s", p.Pattern, FormatRate(float64(p.Count)/float64(c.Count)))
+	}
+	if more := c.Shapes - len(c.Patterns); more > 0 {
+		parts = append(parts, fmt.Sprintf("+%d more", more))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// LengthRange shows the shortest and longest value length
+func (c *Column) LengthRange() string {
+	if c.Count == 0 {
+		return ""
+	}
+	if c.MinLen == c.MaxLen {
+		return strconv.Itoa(c.MinLen)
+	}
+	return fmt.Sprintf("%d–%d", c.MinLen, c.MaxLen)
+}
+
+// ValueRange shows the numeric range of a number column, or the smallest
+// and largest value of other columns
+func (c *Column) ValueRange() string {
+	switch {
+	case c.Count == 0:
+		return ""
+	case c.Type == TypeNumber && c.Range != nil:
+		return formatNumber(c.Range.Min) + " … " + formatNumber(c.Range.Max)
+	}
+	return c.Min + " … " + c.Max
+}
+
+// FlagSummary joins the flags raised on a column
+func (c *Column) FlagSummary() string {
+	flags := make([]string, len(c.Issues))
+	for i, is := range c.Issues {
+		flags[i] = string(is.Flag)
+	}
+	return strings.Join(flags, ", ")
+}
+
+// FormatRate formats a fraction as a percentage
+func FormatRate(f float64) string {
+	s := strconv.FormatFloat(f*100, 'f', 1, 64)
+	return strings.TrimSuffix(s, ".0") + "%"
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// markdownCell escapes the characters that would break a table cell or
+// list item
+func markdownCell(s string) string {
+	return cellEscaper.Replace(s)
+}
+
+var cellEscaper = strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ", "\r", " ")
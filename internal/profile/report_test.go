@@ -0,0 +1,36 @@
+package profile
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteMarkdownEscapes(t *testing.T) {
+	tests := []struct {
+		name    string
+		example string
+		want    string
+	}{
+		{"newline", "a\nb", "`a b`"},
+		{"carriage return", "a\r\nb\rc", "`a b c`"},
+		{"pipe", "a|b", "`a\\|b`"},
+		{"backtick", "a`b", "`a'b`"},
+	}
+	for _, tt := range tests {
+		reports := []Report{{Sheet: "S", Rows: 1, Columns: []Column{{
+			Letter: "A", Name: "Na|me\nx", Count: 1,
+			Issues: []Issue{{Flag: FlagWhitespace, Count: 1, Example: tt.example}},
+		}}}}
+		var b strings.Builder
+		if err := WriteMarkdown(&b, reports); err != nil {
+			t.Fatal(err)
+		}
+		out := b.String()
+		if !strings.Contains(out, "first at row 1: "+tt.want+"\n") {
+			t.Errorf("%s: example not written as %s in\n%s", tt.name, tt.want, out)
+		}
+		if !strings.Contains(out, "| A | Na\\|me x |") {
+			t.Errorf("%s: column name not escaped in\n%s", tt.name, out)
+		}
+	}
+}
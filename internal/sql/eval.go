@@ -0,0 +1,620 @@
+package sql
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vex/internal/loader"
+)
+
+// value is nil (NULL), float64, string or bool
+type value any
+
+// env is what an expression is evaluated against: one row, or the rows of
+// a group when aggregating
+type env struct {
+	row   []value
+	group [][]value
+}
+
+// eval evaluates an expression
+func (e *env) eval(x expr) (value, error) {
+	switch x := x.(type) {
+	case literal:
+		return x.v, nil
+	case *columnRef:
+		if x.alias != nil {
+			return e.eval(x.alias)
+		}
+		if e.row == nil {
+			return nil, nil
+		}
+		return e.row[x.idx], nil
+	case *unaryExpr:
+		v, err := e.eval(x.x)
+		if err != nil || v == nil {
+			return nil, err
+		}
+		if x.op == "NOT" {
+			return !truthy(v), nil
+		}
+		n, err := toNumber(v, x.pos)
+		if err != nil {
+			return nil, err
+		}
+		return -n, nil
+	case *binaryExpr:
+		return e.binary(x)
+	case *callExpr:
+		if aggregates[x.name] {
+			return e.aggregate(x)
+		}
+		return e.call(x)
+	case *isNullExpr:
+		v, err := e.eval(x.x)
+		if err != nil {
+			return nil, err
+		}
+		return (v == nil) != x.not, nil
+	case *inExpr:
+		v, err := e.eval(x.x)
+		if err != nil || v == nil {
+			return nil, err
+		}
+		for _, item := range x.list {
+			w, err := e.eval(item)
+			if err != nil {
+				return nil, err
+			}
+			if w != nil && compare(v, w) == 0 {
+				return !x.not, nil
+			}
+		}
+		return x.not, nil
+	case *betweenExpr:
+		v, err := e.eval(x.x)
+		if err != nil {
+			return nil, err
+		}
+		lo, err := e.eval(x.lo)
+		if err != nil {
+			return nil, err
+		}
+		hi, err := e.eval(x.hi)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil || lo == nil || hi == nil {
+			return nil, nil
+		}
+		return (compare(v, lo) >= 0 && compare(v, hi) <= 0) != x.not, nil
+	case *likeExpr:
+		v, err := e.eval(x.x)
+		if err != nil {
+			return nil, err
+		}
+		p, err := e.eval(x.pattern)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil || p == nil {
+			return nil, nil
+		}
+		return likePattern(toString(p)).MatchString(toString(v)) != x.not, nil
+	case *caseExpr:
+		var operand value
+		if x.operand != nil {
+			var err error
+			if operand, err = e.eval(x.operand); err != nil {
+				return nil, err
+			}
+		}
+		for _, w := range x.whens {
+			c, err := e.eval(w.cond)
+			if err != nil {
+				return nil, err
+			}
+			if (x.operand == nil && truthy(c)) || (x.operand != nil && operand != nil && c != nil && compare(operand, c) == 0) {
+				return e.eval(w.result)
+			}
+		}
+		if x.els != nil {
+			return e.eval(x.els)
+		}
+		return nil, nil
+	}
+	return nil, nil
+}
+
+func (e *env) binary(x *binaryExpr) (value, error) {
+	l, err := e.eval(x.l)
+	if err != nil {
+		return nil, err
+	}
+
+	// AND and OR only look at the right side when they have to
+	switch x.op {
+	case "AND":
+		if l != nil && !truthy(l) {
+			return false, nil
+		}
+	case "OR":
+		if l != nil && truthy(l) {
+			return true, nil
+		}
+	}
+
+	r, err := e.eval(x.r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch x.op {
+	case "AND":
+		if r != nil && !truthy(r) {
+			return false, nil
+		}
+		if l == nil || r == nil {
+			return nil, nil
+		}
+		return true, nil
+	case "OR":
+		if r != nil && truthy(r) {
+			return true, nil
+		}
+		if l == nil || r == nil {
+			return nil, nil
+		}
+		return false, nil
+	}
+
+	if l == nil || r == nil {
+		return nil, nil
+	}
+
+	switch x.op {
+	case "||":
+		return toString(l) + toString(r), nil
+	case "=":
+		return compare(l, r) == 0, nil
+	case "!=":
+		return compare(l, r) != 0, nil
+	case "<":
+		return compare(l, r) < 0, nil
+	case "<=":
+		return compare(l, r) <= 0, nil
+	case ">":
+		return compare(l, r) > 0, nil
+	case ">=":
+		return compare(l, r) >= 0, nil
+	}
+
+	a, err := toNumber(l, x.pos)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toNumber(r, x.pos)
+	if err != nil {
+		return nil, err
+	}
+	switch x.op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return nil, nil
+		}
+		return a / b, nil
+	case "%":
+		if b == 0 {
+			return nil, nil
+		}
+		return math.Mod(a, b), nil
+	}
+	return nil, errorf(x.pos, "unknown operator %s", x.op)
+}
+
+// aggregate combines an expression over the rows of the group
+func (e *env) aggregate(x *callExpr) (value, error) {
+	if e.group == nil {
+		return nil, errorf(x.pos, "%s is not allowed here", x.name)
+	}
+	if x.star {
+		return float64(len(e.group)), nil
+	}
+	if len(x.args) != 1 {
+		return nil, errorf(x.pos, "%s takes one argument", x.name)
+	}
+
+	var (
+		count    int
+		sum      float64
+		best     value
+		seen     map[string]bool
+		inner    = env{}
+		distinct = x.distinct
+	)
+	if distinct {
+		seen = make(map[string]bool)
+	}
+	for _, row := range e.group {
+		inner.row = row
+		v, err := inner.eval(x.args[0])
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			continue
+		}
+		if distinct {
+			k := toString(v)
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+		}
+		count++
+		switch x.name {
+		case "SUM", "AVG":
+			n, err := toNumber(v, x.pos)
+			if err != nil {
+				return nil, err
+			}
+			sum += n
+		case "MIN":
+			if best == nil || compare(v, best) < 0 {
+				best = v
+			}
+		case "MAX":
+			if best == nil || compare(v, best) > 0 {
+				best = v
+			}
+		}
+	}
+
+	switch x.name {
+	case "COUNT":
+		return float64(count), nil
+	case "SUM":
+		if count == 0 {
+			return nil, nil
+		}
+		return sum, nil
+	case "AVG":
+		if count == 0 {
+			return nil, nil
+		}
+		return sum / float64(count), nil
+	}
+	return best, nil
+}
+
+// functions maps each scalar function to its minimum and maximum number
+// of arguments, -1 for any number
+var functions = map[string][2]int{
+	"UPPER": {1, 1}, "LOWER": {1, 1}, "TRIM": {1, 1}, "LENGTH": {1, 1}, "LEN": {1, 1},
+	"LEFT": {2, 2}, "RIGHT": {2, 2}, "SUBSTR": {2, 3}, "SUBSTRING": {2, 3}, "REPLACE": {3, 3},
+	"CONCAT": {1, -1}, "COALESCE": {1, -1}, "IFNULL": {2, 2}, "IF": {2, 3},
+	"ABS": {1, 1}, "ROUND": {1, 2},
+	"TODAY": {0, 0}, "DATE": {1, 1}, "YEAR": {1, 1}, "MONTH": {1, 1}, "DAY": {1, 1}, "DATEDIFF": {2, 2},
+}
+
+// dateLayout is how dates computed by functions are written
+const dateLayout = "2006-01-02"
+
+// checkCall reports calls of unknown scalar functions or with the wrong
+// number of arguments
+func checkCall(x *callExpr) error {
+	if aggregates[x.name] {
+		return nil
+	}
+	arity, ok := functions[x.name]
+	if !ok {
+		return errorf(x.pos, "unknown function %s", x.name)
+	}
+	if n := len(x.args); n < arity[0] || (arity[1] >= 0 && n > arity[1]) {
+		switch {
+		case arity[0] == arity[1]:
+			return errorf(x.pos, "%s takes %d argument(s)", x.name, arity[0])
+		case arity[1] < 0:
+			return errorf(x.pos, "%s takes at least %d argument(s)", x.name, arity[0])
+		}
+		return errorf(x.pos, "%s takes %d to %d arguments", x.name, arity[0], arity[1])
+	}
+	return nil
+}
+
+// call evaluates a scalar function
+func (e *env) call(x *callExpr) (value, error) {
+	if err := checkCall(x); err != nil {
+		return nil, err
+	}
+
+	// IF only evaluates the branch it returns
+	if x.name == "IF" {
+		c, err := e.eval(x.args[0])
+		if err != nil {
+			return nil, err
+		}
+		if truthy(c) {
+			return e.eval(x.args[1])
+		}
+		if len(x.args) == 3 {
+			return e.eval(x.args[2])
+		}
+		return nil, nil
+	}
+
+	args := make([]value, len(x.args))
+	for i, a := range x.args {
+		v, err := e.eval(a)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+
+	switch x.name {
+	case "COALESCE", "IFNULL":
+		for _, v := range args {
+			if v != nil {
+				return v, nil
+			}
+		}
+		return nil, nil
+	case "CONCAT":
+		var b strings.Builder
+		for _, v := range args {
+			if v != nil {
+				b.WriteString(toString(v))
+			}
+		}
+		return b.String(), nil
+	case "TODAY":
+		return time.Now().Format(dateLayout), nil
+	}
+	for _, v := range args {
+		if v == nil {
+			return nil, nil
+		}
+	}
+
+	switch x.name {
+	case "DATE", "YEAR", "MONTH", "DAY":
+		d, err := toDate(args[0], x.pos)
+		if err != nil {
+			return nil, err
+		}
+		switch x.name {
+		case "YEAR":
+			return float64(d.Year()), nil
+		case "MONTH":
+			return float64(d.Month()), nil
+		case "DAY":
+			return float64(d.Day()), nil
+		}
+		return d.Format(dateLayout), nil
+	case "DATEDIFF":
+		a, err := toDate(args[0], x.pos)
+		if err != nil {
+			return nil, err
+		}
+		b, err := toDate(args[1], x.pos)
+		if err != nil {
+			return nil, err
+		}
+		return math.Round(a.Sub(b).Hours() / 24), nil
+	case "LEFT", "RIGHT":
+		s := []rune(toString(args[0]))
+		n, err := toNumber(args[1], x.pos)
+		if err != nil {
+			return nil, err
+		}
+		k := min(max(int(n), 0), len(s))
+		if x.name == "LEFT" {
+			return string(s[:k]), nil
+		}
+		return string(s[len(s)-k:]), nil
+	}
+
+	switch x.name {
+	case "UPPER":
+		return strings.ToUpper(toString(args[0])), nil
+	case "LOWER":
+		return strings.ToLower(toString(args[0])), nil
+	case "TRIM":
+		return strings.TrimSpace(toString(args[0])), nil
+	case "LENGTH", "LEN":
+		return float64(len([]rune(toString(args[0])))), nil
+	case "REPLACE":
+		return strings.ReplaceAll(toString(args[0]), toString(args[1]), toString(args[2])), nil
+	case "SUBSTR", "SUBSTRING":
+		s := []rune(toString(args[0]))
+		start, err := toNumber(args[1], x.pos)
+		if err != nil {
+			return nil, err
+		}
+		from := min(max(int(start)-1, 0), len(s))
+		to := len(s)
+		if len(args) == 3 {
+			n, err := toNumber(args[2], x.pos)
+			if err != nil {
+				return nil, err
+			}
+			to = min(from+max(int(n), 0), len(s))
+		}
+		return string(s[from:to]), nil
+	}
+
+	n, err := toNumber(args[0], x.pos)
+	if err != nil {
+		return nil, err
+	}
+	if x.name == "ABS" {
+		return math.Abs(n), nil
+	}
+	digits := 0.0
+	if len(args) == 2 {
+		if digits, err = toNumber(args[1], x.pos); err != nil {
+			return nil, err
+		}
+	}
+	scale := math.Pow(10, math.Trunc(digits))
+	return math.Round(n*scale) / scale, nil
+}
+
+// hasAggregate reports whether an expression contains an aggregate call
+func hasAggregate(x expr) bool {
+	found := false
+	walk(x, func(x expr) {
+		if c, ok := x.(*callExpr); ok && aggregates[c.name] {
+			found = true
+		}
+	})
+	return found
+}
+
+// walk calls fn for every node of an expression tree
+func walk(x expr, fn func(expr)) {
+	if x == nil {
+		return
+	}
+	fn(x)
+	switch x := x.(type) {
+	case *unaryExpr:
+		walk(x.x, fn)
+	case *binaryExpr:
+		walk(x.l, fn)
+		walk(x.r, fn)
+	case *callExpr:
+		for _, a := range x.args {
+			walk(a, fn)
+		}
+	case *isNullExpr:
+		walk(x.x, fn)
+	case *inExpr:
+		walk(x.x, fn)
+		for _, item := range x.list {
+			walk(item, fn)
+		}
+	case *betweenExpr:
+		walk(x.x, fn)
+		walk(x.lo, fn)
+		walk(x.hi, fn)
+	case *likeExpr:
+		walk(x.x, fn)
+		walk(x.pattern, fn)
+	case *caseExpr:
+		walk(x.operand, fn)
+		for _, w := range x.whens {
+			walk(w.cond, fn)
+			walk(w.result, fn)
+		}
+		walk(x.els, fn)
+	}
+}
+
+// truthy reports whether a value counts as true in a condition
+func truthy(v value) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	}
+	return false
+}
+
+// toNumber converts a value for arithmetic
+func toNumber(v value, pos int) (float64, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		if n, ok := loader.ParseNumber(v); ok {
+			return n, nil
+		}
+		return 0, errorf(pos, "%q is not a number", v)
+	}
+	return 0, nil
+}
+
+// toDate converts a value for date functions
+func toDate(v value, pos int) (time.Time, error) {
+	if d, ok := loader.ParseDate(toString(v)); ok {
+		return d, nil
+	}
+	return time.Time{}, errorf(pos, "%q is not a date", toString(v))
+}
+
+// toString formats a value as cell text
+func toString(v value) string {
+	switch v := v.(type) {
+	case float64:
+		// 15 significant digits hide binary rounding such as 0.1+0.2
+		v, _ = strconv.ParseFloat(strconv.FormatFloat(v, 'g', 15, 64), 64)
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	return ""
+}
+
+// compare orders two non-NULL values: numbers numerically, a number and
+// a numeric string as numbers, anything else like the sheet sorts cells
+func compare(a, b value) int {
+	x, aNum := a.(float64)
+	y, bNum := b.(float64)
+	if aNum && !bNum {
+		y, bNum = loader.ParseNumber(toString(b))
+	} else if bNum && !aNum {
+		x, aNum = loader.ParseNumber(toString(a))
+	}
+	if aNum && bNum {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return loader.CompareValues(toString(a), toString(b))
+}
+
+// likePattern converts a LIKE pattern to a case-insensitive regexp
+func likePattern(p string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("(?is)^")
+	for _, r := range p {
+		switch r {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
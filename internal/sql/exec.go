@@ -0,0 +1,566 @@
+package sql
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/vex/internal/loader"
+	"github.com/vex/internal/ui"
+	"github.com/vex/pkg/models"
+)
+
+// Table is a sheet as the query sees it: named columns whose values are
+// numbers when every non-blank cell of the column is one, text otherwise,
+// and NULL when blank
+type Table struct {
+	Name    string
+	Columns []string
+	rows    [][]value
+}
+
+// TableFromSheet builds the table of a sheet from the rows below the
+// header row
+func TableFromSheet(name string, sheet *models.Sheet, headerRow int) Table {
+	t := Table{Name: name, Columns: make([]string, sheet.MaxCols)}
+	for col := range t.Columns {
+		t.Columns[col] = strings.TrimSpace(sheet.CellAt(headerRow, col).Value)
+		if t.Columns[col] == "" {
+			t.Columns[col] = ui.ColIndexToLetter(col)
+		}
+	}
+
+	numeric := make([]bool, sheet.MaxCols)
+	for col := range numeric {
+		numeric[col] = true
+	}
+	var texts [][]string
+	for row := headerRow + 1; row < sheet.MaxRows; row++ {
+		text := make([]string, sheet.MaxCols)
+		for col := range text {
+			text[col] = strings.TrimSpace(sheet.CellAt(row, col).Value)
+			if _, ok := loader.ParseNumber(text[col]); text[col] != "" && !ok {
+				numeric[col] = false
+			}
+		}
+		texts = append(texts, text)
+	}
+
+	t.rows = make([][]value, len(texts))
+	for i, text := range texts {
+		row := make([]value, len(text))
+		for col, s := range text {
+			switch {
+			case s == "":
+			case numeric[col]:
+				row[col], _ = loader.ParseNumber(s)
+			default:
+				row[col] = s
+			}
+		}
+		t.rows[i] = row
+	}
+	return t
+}
+
+// scopeTable is a table of the FROM clause, placed at offset in the
+// joined row
+type scopeTable struct {
+	name    string // Alias, or the table name
+	table   *Table
+	offset  int
+	columns []string
+}
+
+type scope []scopeTable
+
+// width is the length of a joined row
+func (s scope) width() int {
+	if len(s) == 0 {
+		return 0
+	}
+	last := s[len(s)-1]
+	return last.offset + len(last.columns)
+}
+
+// bind resolves the column references of an expression
+func (s scope) bind(x expr) error {
+	var err error
+	walk(x, func(x expr) {
+		if err != nil {
+			return
+		}
+		switch x := x.(type) {
+		case *columnRef:
+			if x.alias == nil {
+				x.idx, err = s.resolve(x)
+			}
+		case *callExpr:
+			err = checkCall(x)
+		}
+	})
+	return err
+}
+
+// bindHaving binds a HAVING condition, where a name that is not a column
+// can refer to a select item by its alias
+func (s scope) bindHaving(x expr, items []selectItem) error {
+	walk(x, func(x expr) {
+		ref, ok := x.(*columnRef)
+		if !ok || ref.table != "" {
+			return
+		}
+		if _, err := s.resolve(ref); err == nil {
+			return
+		}
+		for _, item := range items {
+			if item.alias != "" && strings.EqualFold(item.alias, ref.name) {
+				ref.alias = item.e
+			}
+		}
+	})
+	return s.bind(x)
+}
+
+// resolve finds the row index of a column
+func (s scope) resolve(ref *columnRef) (int, error) {
+	found := -1
+	for _, t := range s {
+		if ref.table != "" && !strings.EqualFold(ref.table, t.name) {
+			continue
+		}
+		for i, c := range t.columns {
+			if !strings.EqualFold(c, ref.name) {
+				continue
+			}
+			if found >= 0 && ref.table == "" {
+				return 0, errorf(ref.pos, "ambiguous column %q", ref.name)
+			}
+			if found < 0 {
+				found = t.offset + i
+			}
+		}
+	}
+	if found >= 0 {
+		return found, nil
+	}
+	if ref.table != "" && s.table(ref.table) == nil {
+		return 0, errorf(ref.pos, "unknown table %q", ref.table)
+	}
+	return 0, errorf(ref.pos, "unknown column %q", ref.name)
+}
+
+func (s scope) table(name string) *scopeTable {
+	for i := range s {
+		if strings.EqualFold(s[i].name, name) {
+			return &s[i]
+		}
+	}
+	return nil
+}
+
+// Run executes a SELECT query over the given tables. The result has the
+// column names as its first row.
+func Run(query string, tables []Table) ([][]string, error) {
+	stmt, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := func(ref tableRef) (scopeTable, error) {
+		for i := range tables {
+			if strings.EqualFold(tables[i].Name, ref.name) {
+				name := ref.alias
+				if name == "" {
+					name = ref.name
+				}
+				return scopeTable{name: name, table: &tables[i], columns: tables[i].Columns}, nil
+			}
+		}
+		return scopeTable{}, errorf(ref.pos, "unknown table %q", ref.name)
+	}
+
+	// FROM and JOIN
+	first, err := lookup(stmt.from)
+	if err != nil {
+		return nil, err
+	}
+	sc := scope{first}
+	rows := first.table.rows
+	for _, j := range stmt.joins {
+		t, err := lookup(j.table)
+		if err != nil {
+			return nil, err
+		}
+		if sc.table(t.name) != nil {
+			return nil, errorf(j.table.pos, "table %q is used twice, give it an alias", t.name)
+		}
+		t.offset = sc.width()
+		sc = append(sc, t)
+		if err := sc.bind(j.on); err != nil {
+			return nil, err
+		}
+		if rows, err = join(rows, t, j, sc.width()); err != nil {
+			return nil, err
+		}
+	}
+
+	// WHERE
+	if stmt.where != nil {
+		if err := sc.bind(stmt.where); err != nil {
+			return nil, err
+		}
+		if hasAggregate(stmt.where) {
+			return nil, errorf(aggregatePos(stmt.where), "aggregates are not allowed in WHERE")
+		}
+		kept := rows[:0:0]
+		for _, row := range rows {
+			e := env{row: row}
+			v, err := e.eval(stmt.where)
+			if err != nil {
+				return nil, err
+			}
+			if v != nil && truthy(v) {
+				kept = append(kept, row)
+			}
+		}
+		rows = kept
+	}
+
+	// Output columns
+	var (
+		header []string
+		items  []expr
+	)
+	grouped := len(stmt.groupBy) > 0 || stmt.having != nil
+	column := make([]int, len(stmt.items)) // Output column of each item
+	for n, item := range stmt.items {
+		column[n] = len(header)
+		if !item.star {
+			if err := sc.bind(item.e); err != nil {
+				return nil, err
+			}
+			name := item.alias
+			if name == "" {
+				name = item.text
+			}
+			header = append(header, name)
+			items = append(items, item.e)
+			grouped = grouped || hasAggregate(item.e)
+			continue
+		}
+		expanded := false
+		for _, t := range sc {
+			if item.table != "" && !strings.EqualFold(item.table, t.name) {
+				continue
+			}
+			for i, c := range t.columns {
+				header = append(header, c)
+				items = append(items, &columnRef{name: c, idx: t.offset + i})
+			}
+			expanded = true
+		}
+		if !expanded {
+			return nil, errorf(item.pos, "unknown table %q", item.table)
+		}
+	}
+
+	// ORDER BY may name an output column by alias or position
+	order := make([]int, len(stmt.orderBy))
+	for i, o := range stmt.orderBy {
+		order[i] = -1
+		switch x := o.e.(type) {
+		case literal:
+			if n, ok := x.v.(float64); ok && n >= 1 && n == math.Trunc(n) && int(n) <= len(header) {
+				order[i] = int(n) - 1
+			}
+		case *columnRef:
+			for n, item := range stmt.items {
+				if x.table == "" && item.alias != "" && strings.EqualFold(item.alias, x.name) {
+					order[i] = column[n]
+				}
+			}
+		}
+		if order[i] < 0 {
+			if err := sc.bind(o.e); err != nil {
+				return nil, err
+			}
+			grouped = grouped || hasAggregate(o.e)
+		}
+	}
+
+	// GROUP BY and HAVING
+	var envs []env
+	if grouped {
+		for _, g := range stmt.groupBy {
+			if err := sc.bind(g); err != nil {
+				return nil, err
+			}
+			if hasAggregate(g) {
+				return nil, errorf(aggregatePos(g), "aggregates are not allowed in GROUP BY")
+			}
+		}
+		if envs, err = group(rows, stmt.groupBy); err != nil {
+			return nil, err
+		}
+		if stmt.having != nil {
+			if err := sc.bindHaving(stmt.having, stmt.items); err != nil {
+				return nil, err
+			}
+			kept := envs[:0:0]
+			for _, e := range envs {
+				v, err := e.eval(stmt.having)
+				if err != nil {
+					return nil, err
+				}
+				if v != nil && truthy(v) {
+					kept = append(kept, e)
+				}
+			}
+			envs = kept
+		}
+	} else {
+		envs = make([]env, len(rows))
+		for i, row := range rows {
+			envs[i] = env{row: row}
+		}
+	}
+
+	// SELECT, DISTINCT and ORDER BY
+	type outRow struct {
+		values []value
+		keys   []value
+	}
+	out := make([]outRow, 0, len(envs))
+	seen := make(map[string]bool)
+	for _, e := range envs {
+		r := outRow{values: make([]value, len(items)), keys: make([]value, len(order))}
+		for i, x := range items {
+			if r.values[i], err = e.eval(x); err != nil {
+				return nil, err
+			}
+		}
+		if stmt.distinct {
+			k := rowKey(r.values)
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+		}
+		for i, o := range stmt.orderBy {
+			if order[i] >= 0 {
+				r.keys[i] = r.values[order[i]]
+			} else if r.keys[i], err = e.eval(o.e); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, r)
+	}
+	if len(order) > 0 {
+		sort.SliceStable(out, func(a, b int) bool {
+			for i, o := range stmt.orderBy {
+				c := compareNullsLast(out[a].keys[i], out[b].keys[i])
+				if o.desc {
+					c = -c
+				}
+				if c != 0 {
+					return c < 0
+				}
+			}
+			return false
+		})
+	}
+
+	// LIMIT and OFFSET
+	out = out[min(stmt.offset, len(out)):]
+	if stmt.limit >= 0 && stmt.limit < len(out) {
+		out = out[:stmt.limit]
+	}
+
+	result := make([][]string, 0, len(out)+1)
+	result = append(result, header)
+	for _, r := range out {
+		line := make([]string, len(r.values))
+		for i, v := range r.values {
+			line[i] = toString(v)
+		}
+		result = append(result, line)
+	}
+	return result, nil
+}
+
+// join combines rows with the rows of t. An equality between a column of
+// the joined rows and a column of t uses a hash join; any other condition
+// is checked for every pair of rows.
+func join(rows [][]value, t scopeTable, j joinClause, width int) ([][]value, error) {
+	combine := func(l, r []value) []value {
+		row := make([]value, width)
+		copy(row, l)
+		if r != nil {
+			copy(row[t.offset:], r)
+		}
+		return row
+	}
+
+	var out [][]value
+	if l, r, ok := equiJoin(j.on, t.offset); ok {
+		index := make(map[string][]int)
+		for i, row := range t.table.rows {
+			if v := row[r-t.offset]; v != nil {
+				k := joinKey(v)
+				index[k] = append(index[k], i)
+			}
+		}
+		for _, row := range rows {
+			var matches []int
+			if v := row[l]; v != nil {
+				matches = index[joinKey(v)]
+			}
+			for _, i := range matches {
+				out = append(out, combine(row, t.table.rows[i]))
+			}
+			if len(matches) == 0 && j.left {
+				out = append(out, combine(row, nil))
+			}
+		}
+		return out, nil
+	}
+
+	for _, row := range rows {
+		matched := false
+		for _, other := range t.table.rows {
+			joined := combine(row, other)
+			e := env{row: joined}
+			v, err := e.eval(j.on)
+			if err != nil {
+				return nil, err
+			}
+			if v != nil && truthy(v) {
+				out = append(out, joined)
+				matched = true
+			}
+		}
+		if !matched && j.left {
+			out = append(out, combine(row, nil))
+		}
+	}
+	return out, nil
+}
+
+// equiJoin reports whether a join condition is an equality between a
+// column before offset and one from offset on, returning their indexes
+func equiJoin(on expr, offset int) (int, int, bool) {
+	b, ok := on.(*binaryExpr)
+	if !ok || b.op != "=" {
+		return 0, 0, false
+	}
+	l, lok := b.l.(*columnRef)
+	r, rok := b.r.(*columnRef)
+	if !lok || !rok {
+		return 0, 0, false
+	}
+	switch {
+	case l.idx < offset && r.idx >= offset:
+		return l.idx, r.idx, true
+	case r.idx < offset && l.idx >= offset:
+		return r.idx, l.idx, true
+	}
+	return 0, 0, false
+}
+
+// joinKey is the hash key of a join value, matching how compare finds
+// numbers and text equal
+func joinKey(v value) string {
+	s := toString(v)
+	if n, ok := loader.ParseNumber(s); ok {
+		return toString(n)
+	}
+	return strings.ToLower(s)
+}
+
+// group splits rows into groups with equal GROUP BY values, in the order
+// the groups first appear. Without GROUP BY every row is one group.
+func group(rows [][]value, by []expr) ([]env, error) {
+	if len(by) == 0 {
+		e := env{group: rows}
+		if len(rows) > 0 {
+			e.row = rows[0]
+		}
+		if e.group == nil {
+			e.group = [][]value{}
+		}
+		return []env{e}, nil
+	}
+
+	var envs []env
+	index := make(map[string]int)
+	key := make([]value, len(by))
+	for _, row := range rows {
+		e := env{row: row}
+		for i, x := range by {
+			v, err := e.eval(x)
+			if err != nil {
+				return nil, err
+			}
+			key[i] = v
+		}
+		k := rowKey(key)
+		i, ok := index[k]
+		if !ok {
+			i = len(envs)
+			index[k] = i
+			envs = append(envs, env{row: row})
+		}
+		envs[i].group = append(envs[i].group, row)
+	}
+	return envs, nil
+}
+
+// groupKey is the hash key of a GROUP BY or DISTINCT value. Unlike joins,
+// grouping tells "North" from "north", as pivot tables and duplicate
+// detection do.
+func groupKey(v value) string {
+	s := toString(v)
+	if n, ok := loader.ParseNumber(s); ok {
+		return toString(n)
+	}
+	return s
+}
+
+// rowKey is a map key for a list of values that tells NULL from ""
+func rowKey(values []value) string {
+	var b strings.Builder
+	for _, v := range values {
+		if v == nil {
+			b.WriteString("\x01")
+		} else {
+			b.WriteString(groupKey(v))
+		}
+		b.WriteString("\x00")
+	}
+	return b.String()
+}
+
+// compareNullsLast orders values for ORDER BY, NULL after everything
+func compareNullsLast(a, b value) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return compare(a, b)
+}
+
+// aggregatePos returns the position of the first aggregate call
+func aggregatePos(x expr) int {
+	pos := -1
+	walk(x, func(x expr) {
+		if c, ok := x.(*callExpr); ok && aggregates[c.name] && pos < 0 {
+			pos = c.pos
+		}
+	})
+	return pos
+}
@@ -0,0 +1,130 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vex/pkg/models"
+)
+
+func testTables() []Table {
+	orders := models.NewSheet("Orders", [][]string{
+		{"ID", "Region", "Customer", "Amount"},
+		{"1", "North", "Ann", "100"},
+		{"2", "South", "Bob", "250"},
+		{"3", "North", "Cid", "50"},
+		{"4", "East", "Ann", ""},
+		{"5", "South", "Dee", "1,000"},
+	})
+	customers := models.NewSheet("Customers", [][]string{
+		{"Name", "City"},
+		{"Ann", "Oslo"},
+		{"Bob", "Rome"},
+		{"Eve", "Lima"},
+	})
+	return []Table{
+		TableFromSheet("Orders", &orders, 0),
+		TableFromSheet("Customers", &customers, 0),
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  [][]string
+	}{
+		{
+			name:  "select where order",
+			query: "SELECT ID, Amount FROM Orders WHERE Amount > 75 ORDER BY Amount DESC",
+			want:  [][]string{{"ID", "Amount"}, {"5", "1000"}, {"2", "250"}, {"1", "100"}},
+		},
+		{
+			name:  "inner join",
+			query: "SELECT o.ID, c.City FROM Orders o JOIN Customers c ON o.Customer = c.Name ORDER BY o.ID",
+			want:  [][]string{{"ID", "City"}, {"1", "Oslo"}, {"2", "Rome"}, {"4", "Oslo"}},
+		},
+		{
+			name:  "left join keeps unmatched rows",
+			query: "SELECT c.Name, o.ID FROM Customers c LEFT JOIN Orders o ON o.Customer = c.Name ORDER BY c.Name, o.ID",
+			want:  [][]string{{"Name", "ID"}, {"Ann", "1"}, {"Ann", "4"}, {"Bob", "2"}, {"Eve", ""}},
+		},
+		{
+			name:  "group by",
+			query: "SELECT Region, COUNT(*) AS Orders, SUM(Amount) AS Total FROM Orders GROUP BY Region ORDER BY Region",
+			want:  [][]string{{"Region", "Orders", "Total"}, {"East", "1", ""}, {"North", "2", "150"}, {"South", "2", "1250"}},
+		},
+		{
+			name:  "having",
+			query: "SELECT Region, SUM(Amount) AS Total FROM Orders GROUP BY Region HAVING SUM(Amount) > 200",
+			want:  [][]string{{"Region", "Total"}, {"South", "1250"}},
+		},
+		{
+			name:  "distinct",
+			query: "SELECT DISTINCT Region FROM Orders ORDER BY 1",
+			want:  [][]string{{"Region"}, {"East"}, {"North"}, {"South"}},
+		},
+		{
+			name:  "count skips nulls",
+			query: "SELECT COUNT(*), COUNT(Amount), AVG(Amount) FROM Orders",
+			want:  [][]string{{"COUNT(*)", "COUNT(Amount)", "AVG(Amount)"}, {"5", "4", "350"}},
+		},
+		{
+			name:  "is null",
+			query: "SELECT ID FROM Orders WHERE Amount IS NULL",
+			want:  [][]string{{"ID"}, {"4"}},
+		},
+		{
+			name:  "comparison with null is not true",
+			query: "SELECT ID FROM Orders WHERE NOT Amount > 75 ORDER BY ID",
+			want:  [][]string{{"ID"}, {"3"}},
+		},
+		{
+			name:  "nulls sort last",
+			query: "SELECT ID FROM Orders ORDER BY Amount LIMIT 2 OFFSET 3",
+			want:  [][]string{{"ID"}, {"5"}, {"4"}},
+		},
+		{
+			name:  "limit",
+			query: "SELECT ID FROM Orders ORDER BY ID DESC LIMIT 2",
+			want:  [][]string{{"ID"}, {"5"}, {"4"}},
+		},
+		{
+			name:  "offset past the end",
+			query: "SELECT ID FROM Orders LIMIT 10 OFFSET 10",
+			want:  [][]string{{"ID"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Run(tt.query, testTables())
+			if err != nil {
+				t.Fatalf("Run(%q) error: %v", tt.query, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Run(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"unknown table", "SELECT * FROM Nope"},
+		{"unknown column", "SELECT Nope FROM Orders"},
+		{"aggregate in where", "SELECT ID FROM Orders WHERE SUM(Amount) > 1"},
+		{"table used twice", "SELECT * FROM Orders JOIN Orders ON 1 = 1"},
+		{"unterminated string", "SELECT ID FROM Orders WHERE Region = 'North"},
+		{"unexpected character", "SELECT ID€ FROM Orders"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Run(tt.query, testTables()); err == nil {
+				t.Errorf("Run(%q) succeeded, want an error", tt.query)
+			}
+		})
+	}
+}
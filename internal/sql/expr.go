@@ -0,0 +1,124 @@
+package sql
+
+import (
+	"strings"
+
+	"github.com/vex/internal/loader"
+	"github.com/vex/internal/ui"
+)
+
+// Expr is an expression over the cells of one row, such as the definition
+// of a computed column
+type Expr struct {
+	Name string // Column name given with AS, or the expression text
+	Text string // Expression without the AS clause
+	x    expr
+	refs []*columnRef
+}
+
+// ParseExpr parses an expression, optionally followed by AS and a name
+func ParseExpr(src string) (*Expr, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: src, toks: toks}
+	x, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	e := &Expr{x: x, Text: strings.TrimSpace(src[:p.peek().pos])}
+	e.Name = e.Text
+	if p.accept("AS") {
+		if e.Name, _, err = p.name("column name"); err != nil {
+			return nil, err
+		}
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, errorf(t.pos, "unexpected %q", t.text)
+	}
+	if pos := aggregatePos(x); pos >= 0 {
+		return nil, errorf(pos, "aggregates are not allowed here")
+	}
+
+	walk(x, func(x expr) {
+		switch x := x.(type) {
+		case *columnRef:
+			e.refs = append(e.refs, x)
+		case *callExpr:
+			if err == nil {
+				err = checkCall(x)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Bind resolves the column names of the expression against the header of
+// a sheet. A name that is no header can be a column letter.
+func (e *Expr) Bind(columns []string) error {
+	sc := scope{{columns: columns}}
+	for _, ref := range e.refs {
+		idx, err := sc.resolve(ref)
+		if err != nil {
+			col, ok := resolveLetter(ref, len(columns))
+			if !ok {
+				return err
+			}
+			idx = col
+		}
+		ref.idx = idx
+	}
+	return nil
+}
+
+// Eval evaluates the expression for a row whose cell text cell returns.
+// Cells that parse as numbers are numbers and blank cells are NULL.
+func (e *Expr) Eval(cell func(col int) (string, error)) (string, error) {
+	row := make(map[int]bool, len(e.refs))
+	width := 0
+	for _, ref := range e.refs {
+		row[ref.idx] = true
+		width = max(width, ref.idx+1)
+	}
+	values := make([]value, width)
+	for col := range row {
+		text, err := cell(col)
+		if err != nil {
+			return "", err
+		}
+		values[col] = cellValue(text)
+	}
+
+	env := env{row: values}
+	v, err := env.eval(e.x)
+	if err != nil {
+		return "", err
+	}
+	return toString(v), nil
+}
+
+// cellValue converts the text of a cell on its own: blank is NULL and
+// numbers are numbers
+func cellValue(text string) value {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	if n, ok := loader.ParseNumber(text); ok {
+		return n
+	}
+	return text
+}
+
+// resolveLetter finds a column by its letter
+func resolveLetter(ref *columnRef, width int) (int, bool) {
+	if ref.table != "" {
+		return 0, false
+	}
+	col := ui.LetterToColIndex(ref.name)
+	return col, col >= 0 && col < width
+}
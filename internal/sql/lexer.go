@@ -0,0 +1,151 @@
+// Package sql runs SELECT queries over sheets, treating the first row of
+// each sheet as its column names.
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Error is a query error at a byte offset of the query text
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos+1)
+}
+
+func errorf(pos int, format string, args ...any) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokQuoted // Quoted identifier: "name", [name] or `name`
+	tokString
+	tokNumber
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// is reports whether the token is the given keyword or operator
+func (t token) is(s string) bool {
+	return (t.kind == tokIdent || t.kind == tokOp) && strings.EqualFold(t.text, s)
+}
+
+// twoCharOps are the operators made of two characters
+var twoCharOps = []string{"<=", ">=", "<>", "!=", "||"}
+
+// lex splits a query into tokens. Identifiers may hold any Unicode
+// letters and digits, so column names such as Umsatz_Größe need no quotes.
+func lex(src string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(src) {
+		c, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(c):
+			i += size
+		case c == '-' && strings.HasPrefix(src[i:], "--"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '\'':
+			text, end, err := lexQuoted(src, i, '\'')
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, token{tokString, text, i})
+			i = end
+		case c == '"' || c == '`':
+			text, end, err := lexQuoted(src, i, byte(c))
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, token{tokQuoted, text, i})
+			i = end
+		case c == '[':
+			end := strings.IndexByte(src[i:], ']')
+			if end < 0 {
+				return nil, errorf(i, "unterminated [name]")
+			}
+			toks = append(toks, token{tokQuoted, src[i+1 : i+end], i})
+			i += end + 1
+		case isDigit(c) || (c == '.' && i+1 < len(src) && isDigit(rune(src[i+1]))):
+			start := i
+			for i < len(src) && (isDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+				i++
+				if i < len(src) && (src[i] == '+' || src[i] == '-') {
+					i++
+				}
+				for i < len(src) && isDigit(rune(src[i])) {
+					i++
+				}
+			}
+			toks = append(toks, token{tokNumber, src[start:i], start})
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(src) {
+				r, n := utf8.DecodeRuneInString(src[i:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				i += n
+			}
+			toks = append(toks, token{tokIdent, src[start:i], start})
+		default:
+			op := string(c)
+			for _, o := range twoCharOps {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if !strings.Contains("+-*/%=<>!|(),.;", string(c)) {
+				return nil, errorf(i, "unexpected character %q", c)
+			}
+			toks = append(toks, token{tokOp, op, i})
+			i += len(op)
+		}
+	}
+	return append(toks, token{tokEOF, "", len(src)}), nil
+}
+
+// isDigit reports whether c is an ASCII digit, the only digits numbers use
+func isDigit(c rune) bool {
+	return '0' <= c && c <= '9'
+}
+
+// lexQuoted reads a quoted string or identifier starting at src[start],
+// where a doubled quote stands for the quote itself
+func lexQuoted(src string, start int, quote byte) (string, int, error) {
+	var b strings.Builder
+	for i := start + 1; i < len(src); i++ {
+		if src[i] != quote {
+			b.WriteByte(src[i])
+			continue
+		}
+		if i+1 < len(src) && src[i+1] == quote {
+			b.WriteByte(quote)
+			i++
+			continue
+		}
+		return b.String(), i + 1, nil
+	}
+	return "", 0, errorf(start, "unterminated %c", quote)
+}
@@ -0,0 +1,52 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLex(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []token
+	}{
+		{"SELECT a_1 FROM t", []token{{tokIdent, "SELECT", 0}, {tokIdent, "a_1", 7}, {tokIdent, "FROM", 11}, {tokIdent, "t", 16}, {tokEOF, "", 17}}},
+		{"Größe>=1.5e3", []token{{tokIdent, "Größe", 0}, {tokOp, ">=", 7}, {tokNumber, "1.5e3", 9}, {tokEOF, "", 14}}},
+		{"名前 <> 'it''s'", []token{{tokIdent, "名前", 0}, {tokOp, "<>", 7}, {tokString, "it's", 10}, {tokEOF, "", 17}}},
+		{"[Unit Price]\u00a0\"a\"\"b\" -- note", []token{{tokQuoted, "Unit Price", 0}, {tokQuoted, `a"b`, 14}, {tokEOF, "", 28}}},
+		{".5", []token{{tokNumber, ".5", 0}, {tokEOF, "", 2}}},
+	}
+	for _, tt := range tests {
+		got, err := lex(tt.src)
+		if err != nil {
+			t.Errorf("lex(%q) error: %v", tt.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lex(%q) = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestLexErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		pos int
+	}{
+		{"a € b", 2},
+		{"'open", 0},
+		{"[open", 0},
+		{"x ٣", 2},
+	}
+	for _, tt := range tests {
+		_, err := lex(tt.src)
+		e, ok := err.(*Error)
+		if !ok {
+			t.Errorf("lex(%q) error = %v, want a position error", tt.src, err)
+			continue
+		}
+		if e.Pos != tt.pos {
+			t.Errorf("lex(%q) error at %d, want %d", tt.src, e.Pos, tt.pos)
+		}
+	}
+}
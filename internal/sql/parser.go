@@ -0,0 +1,683 @@
+package sql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// expr is a node of an expression tree
+type expr interface{}
+
+type literal struct {
+	v value
+}
+
+type columnRef struct {
+	table, name string
+	pos         int
+	idx         int  // Index in the row, set when bound
+	alias       expr // Select item named by the reference, in HAVING
+}
+
+type unaryExpr struct {
+	op  string
+	x   expr
+	pos int
+}
+
+type binaryExpr struct {
+	op   string
+	l, r expr
+	pos  int
+}
+
+type callExpr struct {
+	name     string // Upper case
+	args     []expr
+	star     bool // COUNT(*)
+	distinct bool
+	pos      int
+}
+
+type inExpr struct {
+	x    expr
+	list []expr
+	not  bool
+}
+
+type betweenExpr struct {
+	x, lo, hi expr
+	not       bool
+}
+
+type isNullExpr struct {
+	x   expr
+	not bool
+}
+
+type likeExpr struct {
+	x, pattern expr
+	not        bool
+	pos        int
+}
+
+type caseExpr struct {
+	operand expr
+	whens   []whenClause
+	els     expr
+}
+
+type whenClause struct {
+	cond, result expr
+}
+
+type selectItem struct {
+	e     expr
+	star  bool   // * or table.*
+	table string // Table of table.*
+	alias string
+	text  string // Source text, used to name the column
+	pos   int
+}
+
+type tableRef struct {
+	name, alias string
+	pos         int
+}
+
+type joinClause struct {
+	table tableRef
+	on    expr
+	left  bool
+}
+
+type orderItem struct {
+	e    expr
+	desc bool
+}
+
+type selectStmt struct {
+	distinct bool
+	items    []selectItem
+	from     tableRef
+	joins    []joinClause
+	where    expr
+	groupBy  []expr
+	having   expr
+	orderBy  []orderItem
+	limit    int // -1 for no limit
+	offset   int
+}
+
+// aggregates are the functions that combine the rows of a group
+var aggregates = map[string]bool{"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true}
+
+// reserved are keywords that end an expression or cannot be an alias
+var reserved = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "GROUP": true, "BY": true, "HAVING": true,
+	"ORDER": true, "LIMIT": true, "OFFSET": true, "JOIN": true, "INNER": true, "LEFT": true,
+	"OUTER": true, "ON": true, "AS": true, "AND": true, "OR": true, "NOT": true, "ASC": true,
+	"DESC": true, "DISTINCT": true, "UNION": true, "CASE": true, "WHEN": true, "THEN": true,
+	"ELSE": true, "END": true, "IN": true, "IS": true, "NULL": true, "LIKE": true, "BETWEEN": true,
+}
+
+type parser struct {
+	src  string
+	toks []token
+	i    int
+}
+
+// parse parses a SELECT statement
+func parse(src string) (*selectStmt, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: src, toks: toks}
+	stmt, err := p.selectStmt()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().is(";") {
+		p.next()
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, errorf(t.pos, "unexpected %q", t.text)
+	}
+	return stmt, nil
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the given keyword or operator
+func (p *parser) accept(s string) bool {
+	if p.peek().is(s) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(s string) error {
+	if !p.accept(s) {
+		return p.unexpected("expected " + strings.ToUpper(s))
+	}
+	return nil
+}
+
+// unexpected reports the next token as an error
+func (p *parser) unexpected(what string) error {
+	t := p.peek()
+	if t.kind == tokEOF {
+		return errorf(t.pos, "%s, found end of query", what)
+	}
+	return errorf(t.pos, "%s, found %q", what, t.text)
+}
+
+// name reads an identifier or quoted identifier
+func (p *parser) name(what string) (string, int, error) {
+	t := p.peek()
+	if t.kind == tokQuoted || (t.kind == tokIdent && !reserved[strings.ToUpper(t.text)]) {
+		p.next()
+		return t.text, t.pos, nil
+	}
+	return "", 0, p.unexpected("expected " + what)
+}
+
+func (p *parser) selectStmt() (*selectStmt, error) {
+	if err := p.expect("SELECT"); err != nil {
+		return nil, err
+	}
+	stmt := &selectStmt{limit: -1}
+	if p.accept("DISTINCT") {
+		stmt.distinct = true
+	} else {
+		p.accept("ALL")
+	}
+
+	for {
+		item, err := p.selectItem()
+		if err != nil {
+			return nil, err
+		}
+		stmt.items = append(stmt.items, item)
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+	from, err := p.tableRef()
+	if err != nil {
+		return nil, err
+	}
+	stmt.from = from
+
+	for {
+		var j joinClause
+		switch {
+		case p.accept("JOIN"):
+		case p.accept("INNER"):
+			if err := p.expect("JOIN"); err != nil {
+				return nil, err
+			}
+		case p.accept("LEFT"):
+			p.accept("OUTER")
+			if err := p.expect("JOIN"); err != nil {
+				return nil, err
+			}
+			j.left = true
+		case p.accept(","):
+			// Comma join, filtered by WHERE
+			j.on = literal{v: true}
+		default:
+			goto clauses
+		}
+		if j.table, err = p.tableRef(); err != nil {
+			return nil, err
+		}
+		if j.on == nil {
+			if err := p.expect("ON"); err != nil {
+				return nil, err
+			}
+			if j.on, err = p.expr(); err != nil {
+				return nil, err
+			}
+		}
+		stmt.joins = append(stmt.joins, j)
+	}
+
+clauses:
+	if p.accept("WHERE") {
+		if stmt.where, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("GROUP") {
+		if err := p.expect("BY"); err != nil {
+			return nil, err
+		}
+		if stmt.groupBy, err = p.exprList(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("HAVING") {
+		if stmt.having, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("ORDER") {
+		if err := p.expect("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			e, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			item := orderItem{e: e}
+			if p.accept("DESC") {
+				item.desc = true
+			} else {
+				p.accept("ASC")
+			}
+			stmt.orderBy = append(stmt.orderBy, item)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	if p.accept("LIMIT") {
+		if stmt.limit, err = p.count(); err != nil {
+			return nil, err
+		}
+		if p.accept("OFFSET") {
+			if stmt.offset, err = p.count(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return stmt, nil
+}
+
+// count reads a non-negative integer
+func (p *parser) count() (int, error) {
+	t := p.peek()
+	n, err := strconv.Atoi(t.text)
+	if t.kind != tokNumber || err != nil || n < 0 {
+		return 0, p.unexpected("expected a row count")
+	}
+	p.next()
+	return n, nil
+}
+
+func (p *parser) selectItem() (selectItem, error) {
+	start := p.peek().pos
+	if p.accept("*") {
+		return selectItem{star: true}, nil
+	}
+	// table.*
+	if t := p.peek(); (t.kind == tokIdent || t.kind == tokQuoted) && p.toks[p.i+1].is(".") && p.toks[p.i+2].is("*") {
+		p.i += 3
+		return selectItem{star: true, table: t.text, pos: t.pos}, nil
+	}
+
+	e, err := p.expr()
+	if err != nil {
+		return selectItem{}, err
+	}
+	item := selectItem{e: e, text: strings.TrimSpace(p.src[start:p.peek().pos])}
+	if p.accept("AS") {
+		if item.alias, _, err = p.name("column alias"); err != nil {
+			return selectItem{}, err
+		}
+	} else if t := p.peek(); t.kind == tokQuoted || (t.kind == tokIdent && !reserved[strings.ToUpper(t.text)]) {
+		item.alias = p.next().text
+	}
+	if ref, ok := e.(*columnRef); ok && item.alias == "" {
+		item.text = ref.name
+	}
+	return item, nil
+}
+
+func (p *parser) tableRef() (tableRef, error) {
+	name, pos, err := p.name("table name")
+	if err != nil {
+		return tableRef{}, err
+	}
+	ref := tableRef{name: name, pos: pos}
+	if p.accept("AS") {
+		if ref.alias, _, err = p.name("table alias"); err != nil {
+			return tableRef{}, err
+		}
+	} else if t := p.peek(); t.kind == tokQuoted || (t.kind == tokIdent && !reserved[strings.ToUpper(t.text)]) {
+		ref.alias = p.next().text
+	}
+	return ref, nil
+}
+
+func (p *parser) exprList() ([]expr, error) {
+	var list []expr
+	for {
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+		if !p.accept(",") {
+			return list, nil
+		}
+	}
+}
+
+// expr parses an expression. Precedence from loosest: OR, AND, NOT,
+// comparison, ||, + -, * / %, unary minus.
+func (p *parser) expr() (expr, error) {
+	return p.or()
+}
+
+func (p *parser) or() (expr, error) {
+	l, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("OR") {
+		pos := p.next().pos
+		r, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryExpr{op: "OR", l: l, r: r, pos: pos}
+	}
+	return l, nil
+}
+
+func (p *parser) and() (expr, error) {
+	l, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("AND") {
+		pos := p.next().pos
+		r, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryExpr{op: "AND", l: l, r: r, pos: pos}
+	}
+	return l, nil
+}
+
+func (p *parser) not() (expr, error) {
+	if t := p.peek(); t.is("NOT") {
+		p.next()
+		x, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpr{op: "NOT", x: x, pos: t.pos}, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (expr, error) {
+	l, err := p.concat()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	switch {
+	case t.is("=") || t.is("!=") || t.is("<>") || t.is("<") || t.is("<=") || t.is(">") || t.is(">="):
+		p.next()
+		r, err := p.concat()
+		if err != nil {
+			return nil, err
+		}
+		op := t.text
+		if op == "<>" {
+			op = "!="
+		}
+		return &binaryExpr{op: op, l: l, r: r, pos: t.pos}, nil
+	case t.is("IS"):
+		p.next()
+		not := p.accept("NOT")
+		if err := p.expect("NULL"); err != nil {
+			return nil, err
+		}
+		return &isNullExpr{x: l, not: not}, nil
+	}
+
+	not := false
+	if t.is("NOT") {
+		if n := p.toks[p.i+1]; n.is("IN") || n.is("LIKE") || n.is("BETWEEN") {
+			p.next()
+			not = true
+			t = p.peek()
+		}
+	}
+	switch {
+	case t.is("IN"):
+		p.next()
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		list, err := p.exprList()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return &inExpr{x: l, list: list, not: not}, nil
+	case t.is("LIKE"):
+		p.next()
+		pattern, err := p.concat()
+		if err != nil {
+			return nil, err
+		}
+		return &likeExpr{x: l, pattern: pattern, not: not, pos: t.pos}, nil
+	case t.is("BETWEEN"):
+		p.next()
+		lo, err := p.concat()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("AND"); err != nil {
+			return nil, err
+		}
+		hi, err := p.concat()
+		if err != nil {
+			return nil, err
+		}
+		return &betweenExpr{x: l, lo: lo, hi: hi, not: not}, nil
+	}
+	return l, nil
+}
+
+func (p *parser) concat() (expr, error) {
+	return p.binary(p.additive, "||")
+}
+
+func (p *parser) additive() (expr, error) {
+	return p.binary(p.multiplicative, "+", "-")
+}
+
+func (p *parser) multiplicative() (expr, error) {
+	return p.binary(p.unary, "*", "/", "%")
+}
+
+// binary parses a left-associative chain of the given operators
+func (p *parser) binary(operand func() (expr, error), ops ...string) (expr, error) {
+	l, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		matched := false
+		for _, op := range ops {
+			if t.kind == tokOp && t.text == op {
+				matched = true
+			}
+		}
+		if !matched {
+			return l, nil
+		}
+		p.next()
+		r, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryExpr{op: t.text, l: l, r: r, pos: t.pos}
+	}
+}
+
+func (p *parser) unary() (expr, error) {
+	if t := p.peek(); t.is("-") || t.is("+") {
+		p.next()
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if t.text == "+" {
+			return x, nil
+		}
+		return &unaryExpr{op: "-", x: x, pos: t.pos}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (expr, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokNumber:
+		p.next()
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, errorf(t.pos, "invalid number %q", t.text)
+		}
+		return literal{v: n}, nil
+	case tokString:
+		p.next()
+		return literal{v: t.text}, nil
+	case tokOp:
+		if t.text == "(" {
+			p.next()
+			e, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return e, nil
+		}
+		return nil, p.unexpected("expected an expression")
+	case tokEOF:
+		return nil, p.unexpected("expected an expression")
+	}
+
+	switch strings.ToUpper(t.text) {
+	case "NULL":
+		if t.kind == tokIdent {
+			p.next()
+			return literal{v: nil}, nil
+		}
+	case "TRUE", "FALSE":
+		if t.kind == tokIdent {
+			p.next()
+			return literal{v: strings.EqualFold(t.text, "TRUE")}, nil
+		}
+	case "CASE":
+		if t.kind == tokIdent {
+			p.next()
+			return p.caseExpr()
+		}
+	}
+
+	if t.kind == tokIdent && p.toks[p.i+1].is("(") {
+		return p.call()
+	}
+
+	name, pos, err := p.name("an expression")
+	if err != nil {
+		return nil, err
+	}
+	if p.accept(".") {
+		col, _, err := p.name("column name")
+		if err != nil {
+			return nil, err
+		}
+		return &columnRef{table: name, name: col, pos: pos}, nil
+	}
+	return &columnRef{name: name, pos: pos}, nil
+}
+
+func (p *parser) call() (expr, error) {
+	t := p.next()
+	p.next() // (
+	c := &callExpr{name: strings.ToUpper(t.text), pos: t.pos}
+	if p.accept(")") {
+		return c, nil
+	}
+	if aggregates[c.name] && p.accept("*") {
+		c.star = true
+	} else {
+		c.distinct = aggregates[c.name] && p.accept("DISTINCT")
+		args, err := p.exprList()
+		if err != nil {
+			return nil, err
+		}
+		c.args = args
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (p *parser) caseExpr() (expr, error) {
+	c := &caseExpr{}
+	var err error
+	if !p.peek().is("WHEN") {
+		if c.operand, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	for p.accept("WHEN") {
+		var w whenClause
+		if w.cond, err = p.expr(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("THEN"); err != nil {
+			return nil, err
+		}
+		if w.result, err = p.expr(); err != nil {
+			return nil, err
+		}
+		c.whens = append(c.whens, w)
+	}
+	if len(c.whens) == 0 {
+		return nil, p.unexpected("expected WHEN")
+	}
+	if p.accept("ELSE") {
+		if c.els, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("END"); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
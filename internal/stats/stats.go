@@ -0,0 +1,190 @@
+// Package stats summarises the values of a column.
+package stats
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/vex/internal/loader"
+)
+
+// checkEvery is how many values are processed between cancellation checks
+const checkEvery = 4096
+
+// Percentiles are the percentiles reported in a summary
+var Percentiles = []float64{5, 25, 50, 75, 95}
+
+// ValueCount is a value and the number of times it occurs
+type ValueCount struct {
+	Value string
+	Count int
+}
+
+// Summary describes the values of a column. The numeric fields only cover
+// values that parse as numbers and are zero when Numeric is 0.
+type Summary struct {
+	Count    int // Non-blank values
+	Blanks   int
+	Distinct int
+	Min, Max string // Smallest and largest value in sort order
+
+	Numeric     int
+	Sum         float64
+	Mean        float64
+	Median      float64
+	StdDev      float64 // Sample standard deviation
+	Percentiles []float64
+	NumMin      float64
+	NumMax      float64
+	Histogram   []int // Counts of numeric values in equal-width bins from NumMin to NumMax
+
+	Top []ValueCount // Most frequent values, most frequent first
+}
+
+// Summarize computes the summary of a list of cell values with the given
+// number of histogram bins and most frequent values
+func Summarize(ctx context.Context, values []string, bins, topN int) (Summary, error) {
+	var s Summary
+	counts := make(map[string]int)
+	var nums []float64
+
+	for i, v := range values {
+		if i%checkEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return Summary{}, err
+			}
+		}
+		v = strings.TrimSpace(v)
+		if v == "" {
+			s.Blanks++
+			continue
+		}
+		s.Count++
+		counts[v]++
+		if s.Min == "" || loader.CompareValues(v, s.Min) < 0 {
+			s.Min = v
+		}
+		if s.Max == "" || loader.CompareValues(v, s.Max) > 0 {
+			s.Max = v
+		}
+		// Infinite values would leave the histogram without a width
+		if n, ok := loader.ParseNumber(v); ok && !math.IsInf(n, 0) && !math.IsNaN(n) {
+			nums = append(nums, n)
+		}
+	}
+	s.Distinct = len(counts)
+	s.Top = topValues(counts, topN)
+
+	if err := ctx.Err(); err != nil {
+		return Summary{}, err
+	}
+	if len(nums) > 0 {
+		numeric(&s, nums, bins)
+	}
+	return s, nil
+}
+
+// Aggregate is the running summary shown for a selection
+type Aggregate struct {
+	Count    int // Non-blank values
+	Numeric  int
+	Sum      float64
+	Min, Max float64
+}
+
+// Add includes a cell value in the aggregate
+func (a *Aggregate) Add(v string) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return
+	}
+	a.Count++
+	n, ok := loader.ParseNumber(v)
+	if !ok {
+		return
+	}
+	if a.Numeric == 0 || n < a.Min {
+		a.Min = n
+	}
+	if a.Numeric == 0 || n > a.Max {
+		a.Max = n
+	}
+	a.Numeric++
+	a.Sum += n
+}
+
+// Mean returns the average of the numeric values
+func (a Aggregate) Mean() float64 {
+	if a.Numeric == 0 {
+		return 0
+	}
+	return a.Sum / float64(a.Numeric)
+}
+
+// numeric fills in the numeric fields of a summary
+func numeric(s *Summary, nums []float64, bins int) {
+	sort.Float64s(nums)
+	n := float64(len(nums))
+	s.Numeric = len(nums)
+	s.NumMin, s.NumMax = nums[0], nums[len(nums)-1]
+
+	for _, v := range nums {
+		s.Sum += v
+	}
+	s.Mean = s.Sum / n
+	if len(nums) > 1 {
+		var sq float64
+		for _, v := range nums {
+			sq += (v - s.Mean) * (v - s.Mean)
+		}
+		s.StdDev = math.Sqrt(sq / (n - 1))
+	}
+
+	s.Median = Percentile(nums, 50)
+	s.Percentiles = make([]float64, len(Percentiles))
+	for i, p := range Percentiles {
+		s.Percentiles[i] = Percentile(nums, p)
+	}
+
+	if bins > 0 {
+		s.Histogram = make([]int, bins)
+		width := (s.NumMax - s.NumMin) / float64(bins)
+		for _, v := range nums {
+			b := 0
+			if width > 0 {
+				// Clamped, as a range too wide for a float64 makes width +Inf
+				b = max(0, min(int((v-s.NumMin)/width), bins-1))
+			}
+			s.Histogram[b]++
+		}
+	}
+}
+
+// Percentile interpolates the p-th percentile of sorted values the way
+// Excel's PERCENTILE.INC does
+func Percentile(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := min(lo+1, len(sorted)-1)
+	return sorted[lo] + (rank-float64(lo))*(sorted[hi]-sorted[lo])
+}
+
+// topValues returns the n most frequent values, ties in sort order
+func topValues(counts map[string]int, n int) []ValueCount {
+	top := make([]ValueCount, 0, len(counts))
+	for v, c := range counts {
+		top = append(top, ValueCount{Value: v, Count: c})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return loader.CompareValues(top[i].Value, top[j].Value) < 0
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
@@ -19,6 +19,9 @@ type Styles struct {
 	Title                lipgloss.Style
 	Header               lipgloss.Style
 	HeaderHighlight      lipgloss.Style
+	ComputedHeader       lipgloss.Style
+	Duplicate            lipgloss.Style
+	Outlier              lipgloss.Style
 	Cell                 lipgloss.Style
 	SelectedCell         lipgloss.Style
 	RowHighlight         lipgloss.Style
@@ -64,6 +67,14 @@ func InitStyles() *Styles {
 			Align(lipgloss.Center).
 			Width(MinCellWidth),
 
+		ComputedHeader: lipgloss.NewStyle().
+			Bold(true).
+			Italic(true).
+			Foreground(t.Accent).
+			Background(t.Border).
+			Align(lipgloss.Center).
+			Width(MinCellWidth),
+
 		Cell: lipgloss.NewStyle().
 			Foreground(t.Text).
 			Width(MinCellWidth),
@@ -84,6 +95,17 @@ func InitStyles() *Styles {
 			Background(t.ColHighlight).
 			Width(MinCellWidth),
 
+		Duplicate: lipgloss.NewStyle().
+			Foreground(t.Warning).
+			Italic(true).
+			Width(MinCellWidth),
+
+		Outlier: lipgloss.NewStyle().
+			Foreground(t.Warning).
+			Bold(true).
+			Underline(true).
+			Width(MinCellWidth),
+
 		SearchMatch: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#000000")).
 			Background(t.SearchMatch).
@@ -164,6 +186,27 @@ func ColIndexToLetter(index int) string {
 	return result
 }
 
+// LetterToColIndex converts an Excel-style column letter to a 0-indexed column number.
+// It returns -1 if the input is not a valid column reference.
+func LetterToColIndex(letters string) int {
+	if letters == "" {
+		return -1
+	}
+	col := 0
+	for _, r := range strings.ToUpper(letters) {
+		if r < 'A' || r > 'Z' {
+			return -1
+		}
+		col = col*26 + int(r-'A') + 1
+	}
+	return col - 1
+}
+
+// CellRef formats a 0-indexed row and column as an A1-style reference
+func CellRef(row, col int) string {
+	return ColIndexToLetter(col) + strconv.Itoa(row+1)
+}
+
 // Truncate truncates a string to maxLen with ellipsis
 func Truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
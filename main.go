@@ -2,11 +2,18 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/vex/internal/app"
+	"github.com/vex/internal/clipboard"
+	"github.com/vex/internal/config"
 	"github.com/vex/internal/loader"
+	"github.com/vex/internal/profile"
+	"github.com/vex/pkg/models"
 )
 
 const version = "2.0.0"
@@ -20,6 +27,29 @@ func main() {
 	filename := os.Args[1]
 	themeName := parseThemeFlag()
 
+	// Check the profile format before spending time loading the files
+	var writeProfile func(io.Writer, []profile.Report) error
+	if format := parseFlag("--profile", "", ""); format != "" {
+		var err error
+		if writeProfile, err = profileWriter(format); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Load user configuration; a broken config should not block viewing files
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	if backend := parseFlag("--clipboard", "", ""); backend != "" {
+		cfg.Clipboard = backend
+	}
+	if err := clipboard.Configure(cfg.Clipboard, cfg.ClipboardTmux); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Validate file exists
 	if err := validateFile(filename); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -38,6 +68,29 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Further files are opened alongside the main one for SQL queries
+	for _, extra := range extraFiles() {
+		if err := validateFile(extra); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		more, err := loader.LoadFile(extra)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading file: %v\n", err)
+			os.Exit(1)
+		}
+		sheets = append(sheets, openedAlongside(extra, more)...)
+	}
+
+	// Print a data profile instead of opening the viewer
+	if writeProfile != nil {
+		if err := writeProfile(os.Stdout, app.Profiles(filename, sheets)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Create and run application
 	model := app.NewModel(filename, sheets, themeName)
 	program := tea.NewProgram(
@@ -54,7 +107,9 @@ func main() {
 
 func printUsage() {
 	fmt.Printf("Excel TUI v%s - Modern Terminal Excel Viewer\n\n", version)
-	fmt.Println("Usage: vex <file> [--theme <name>]")
+	fmt.Println("Usage: vex <file> [<file>...] [--theme <name>] [--clipboard auto|system|osc52] [--profile json|markdown]")
+	fmt.Println("\nFiles after the first are opened read-only alongside it, for SQL joins.")
+	fmt.Println("--profile prints a profile of every column to stdout instead of opening the viewer.")
 	fmt.Println("\nAvailable themes:")
 	for _, name := range app.GetThemeNames() {
 		fmt.Printf("  • %s\n", name)
@@ -62,18 +117,68 @@ func printUsage() {
 	fmt.Println("\nExample:")
 	fmt.Println("  vex data.xlsx")
 	fmt.Println("  vex report.csv --theme nord")
+	fmt.Println("  vex sales.xlsx regions.csv")
+	fmt.Println("  vex data.csv --profile markdown > profile.md")
 }
 
 func parseThemeFlag() string {
-	themeName := "catppuccin" // default
+	return parseFlag("--theme", "-t", "catppuccin")
+}
+
+// parseFlag returns the value following a long or short flag, or def
+func parseFlag(long, short, def string) string {
+	value := def
 	for i := 2; i < len(os.Args)-1; i++ {
-		if os.Args[i] == "--theme" || os.Args[i] == "-t" {
-			if i+1 < len(os.Args) {
-				themeName = os.Args[i+1]
-			}
+		if os.Args[i] == long || (short != "" && os.Args[i] == short) {
+			value = os.Args[i+1]
+		}
+	}
+	return value
+}
+
+// valueFlags are the flags followed by a value
+var valueFlags = map[string]bool{"--theme": true, "-t": true, "--clipboard": true, "--profile": true}
+
+// extraFiles returns the file arguments after the first
+func extraFiles() []string {
+	var files []string
+	for i := 2; i < len(os.Args); i++ {
+		switch arg := os.Args[i]; {
+		case valueFlags[arg]:
+			i++
+		case strings.HasPrefix(arg, "-"):
+		default:
+			files = append(files, arg)
 		}
 	}
-	return themeName
+	return files
+}
+
+// openedAlongside marks the sheets of an extra file as not part of the main
+// file. Workbook sheets are named after the file so they stay apart from
+// the main workbook's; a CSV sheet already is.
+func openedAlongside(filename string, sheets []models.Sheet) []models.Sheet {
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	for i := range sheets {
+		if !strings.EqualFold(sheets[i].Name, filepath.Base(filename)) {
+			sheets[i].Name = base + "/" + sheets[i].Name
+		}
+		sheets[i].Virtual = true
+		sheets[i].Source = filename
+	}
+	return sheets
+}
+
+// profileWriter returns the function writing profiles in a format, JSON
+// or Markdown
+func profileWriter(format string) (func(io.Writer, []profile.Report) error, error) {
+	switch strings.ToLower(format) {
+	case "json":
+		return profile.WriteJSON, nil
+	case "markdown", "md":
+		return profile.WriteMarkdown, nil
+	}
+	return nil, fmt.Errorf("unknown profile format '%s' (use json or markdown)", format)
 }
 
 func validateFile(filename string) error {
@@ -10,10 +10,450 @@ type Cell struct {
 
 // Sheet represents a worksheet with its data
 type Sheet struct {
-	Name    string
-	Rows    [][]Cell
-	MaxRows int
-	MaxCols int
+	Name     string
+	Rows     [][]Cell
+	MaxRows  int
+	MaxCols  int
+	Dirty    bool             // Sheet has unsaved changes
+	Edits    []StructuralEdit // Row and column changes not yet saved
+	Filter   *AutoFilter      // Active autofilter, nil when unfiltered
+	Virtual  bool             // Generated in the session, not part of the file
+	Source   string           // File the sheet was opened from, when not the main file
+	Computed []ComputedColumn // Columns whose values come from an expression
+	Formats  []FormatRule     // Conditional formats, highest priority first
+	Warnings []string         // Parts of the file left out when loading the sheet
+}
+
+// NewSheet builds a sheet from rows of values
+func NewSheet(name string, values [][]string) Sheet {
+	s := Sheet{Name: name, Rows: make([][]Cell, len(values)), MaxRows: len(values)}
+	for r, row := range values {
+		cells := make([]Cell, len(row))
+		for c, v := range row {
+			cells[c] = Cell{Value: v, Row: r, Col: c}
+		}
+		s.Rows[r] = cells
+		if len(row) > s.MaxCols {
+			s.MaxCols = len(row)
+		}
+	}
+	return s
+}
+
+// AutoFilter hides the rows of a sheet whose values fail any of its column
+// filters. Rows up to and including HeaderRow are never hidden.
+type AutoFilter struct {
+	HeaderRow int
+	Columns   []ColumnFilter
+	Hidden    []bool // Rows hidden when the filter was last applied
+	Shown     int    // Data rows shown when the filter was last applied
+}
+
+// ColumnFilter keeps the rows whose value in Col is one of Values and
+// satisfies the condition Op Operand. A nil Values keeps every value and
+// an empty Op has no condition.
+type ColumnFilter struct {
+	Col     int
+	Values  map[string]bool // Kept values, trimmed, "" for blanks
+	Op      string          // >, >=, <, <=, =, !=, contains, blank or nonblank
+	Operand string
+}
+
+// Column returns the filter of a column, or nil if it is not filtered
+func (f *AutoFilter) Column(col int) *ColumnFilter {
+	for i := range f.Columns {
+		if f.Columns[i].Col == col {
+			return &f.Columns[i]
+		}
+	}
+	return nil
+}
+
+// IsHidden reports whether the filter hides a row
+func (f *AutoFilter) IsHidden(row int) bool {
+	return row >= 0 && row < len(f.Hidden) && f.Hidden[row]
+}
+
+// ComputedColumn is a column whose cells below the header are computed
+// from an expression over the other cells of their row
+type ComputedColumn struct {
+	Col    int
+	Name   string
+	Expr   string
+	Header int // Row holding the names the expression refers to
+}
+
+// ComputedAt returns the computed column at col, or nil
+func (s *Sheet) ComputedAt(col int) *ComputedColumn {
+	for i := range s.Computed {
+		if s.Computed[i].Col == col {
+			return &s.Computed[i]
+		}
+	}
+	return nil
+}
+
+// ComputedCell returns the computed column whose expression gives the cell
+// at row, col, or nil when the cell holds its own value
+func (s *Sheet) ComputedCell(row, col int) *ComputedColumn {
+	if c := s.ComputedAt(col); c != nil && row > c.Header {
+		return c
+	}
+	return nil
+}
+
+// shiftComputed moves the header rows of the computed columns after n rows
+// are inserted at index at, or -n removed. Columns whose header row is
+// removed are dropped.
+func (s *Sheet) shiftComputed(at, n int) {
+	var kept []ComputedColumn
+	for _, c := range s.Computed {
+		switch {
+		case c.Header < at:
+		case n > 0 || c.Header >= at-n:
+			c.Header += n
+		default:
+			continue
+		}
+		kept = append(kept, c)
+	}
+	s.Computed = kept
+}
+
+// FormatKind is the kind of a conditional format rule
+type FormatKind int
+
+const (
+	FormatCell       FormatKind = iota // Colour cells whose value satisfies Op
+	FormatColorScale                   // Shade cells between the colours of Stops by value
+	FormatDataBar                      // Draw a bar of colour Fill as long as the value is large
+	FormatTopBottom                    // Colour the Rank highest values, or lowest with Bottom
+)
+
+// FormatRule is a conditional format over blocks of cells. Colours are
+// "#RRGGBB" or the name of a theme colour such as "error".
+type FormatRule struct {
+	Kind    FormatKind
+	Ranges  []CellRange
+	Op      string // Cell rules: a filter condition operator, between or notBetween
+	Value   string
+	Value2  string // Upper bound of between and notBetween
+	Fg      string // Text colour of matching cells
+	Fill    string // Background of matching cells, or the colour of a data bar
+	Stops   []FormatStop
+	Rank    int
+	Bottom  bool
+	Percent bool   // Rank is a percentage of the values
+	Rule    string // Text of a rule defined in vex, empty for rules from the file
+}
+
+// CellRange is a block of cells. A LastRow of -1 extends to the end of the
+// sheet.
+type CellRange struct {
+	FirstRow, FirstCol int
+	LastRow, LastCol   int
+}
+
+// Contains reports whether a cell lies in the range
+func (r CellRange) Contains(row, col int) bool {
+	return row >= r.FirstRow && col >= r.FirstCol && col <= r.LastCol && (r.LastRow < 0 || row <= r.LastRow)
+}
+
+// shiftFormats moves the ranges of the conditional formats after n rows or
+// columns are inserted at index at, or -n removed. Ranges grow and shrink
+// with the cells inserted into or removed from them. Ranges whose cells are
+// all removed are dropped, and so are rules left without a range. The
+// rules are rebuilt rather than changed in place so earlier copies of
+// s.Formats, e.g. kept for undo, stay as they were.
+func (s *Sheet) shiftFormats(rows bool, at, n int) {
+	shift := func(i int, last bool) int {
+		switch {
+		case i < at: // Before the change, or a LastRow of -1
+			return i
+		case n > 0 || i >= at-n:
+			return i + n
+		case last:
+			return at - 1
+		}
+		return at
+	}
+
+	var kept []FormatRule
+	for _, f := range s.Formats {
+		var ranges []CellRange
+		for _, rg := range f.Ranges {
+			first, last := &rg.FirstRow, &rg.LastRow
+			if !rows {
+				first, last = &rg.FirstCol, &rg.LastCol
+			}
+			if n < 0 && *first >= at && *last >= 0 && *last < at-n {
+				continue
+			}
+			*first, *last = shift(*first, false), shift(*last, true)
+			ranges = append(ranges, rg)
+		}
+		if len(ranges) > 0 {
+			f.Ranges = ranges
+			kept = append(kept, f)
+		}
+	}
+	s.Formats = kept
+}
+
+// shiftFilter moves the autofilter after n rows or columns are inserted at
+// index at, or -n removed. Filters on removed columns are dropped, and the
+// whole autofilter when its header row or last filtered column is removed.
+// The filter is replaced rather than changed in place, like the formats.
+func (s *Sheet) shiftFilter(rows bool, at, n int) {
+	if s.Filter == nil {
+		return
+	}
+	f := *s.Filter
+	if rows {
+		switch {
+		case f.HeaderRow < at:
+		case n > 0 || f.HeaderRow >= at-n:
+			f.HeaderRow += n
+		default:
+			s.Filter = nil
+			return
+		}
+		if at < len(f.Hidden) {
+			hidden := append([]bool(nil), f.Hidden[:at]...)
+			if n > 0 {
+				hidden = append(hidden, make([]bool, n)...)
+				hidden = append(hidden, f.Hidden[at:]...)
+			} else if at-n < len(f.Hidden) {
+				hidden = append(hidden, f.Hidden[at-n:]...)
+			}
+			f.Hidden = hidden
+		}
+	} else {
+		f.Columns = nil
+		for _, c := range s.Filter.Columns {
+			switch {
+			case c.Col < at:
+			case n > 0 || c.Col >= at-n:
+				c.Col += n
+			default:
+				continue
+			}
+			f.Columns = append(f.Columns, c)
+		}
+		if len(f.Columns) == 0 {
+			s.Filter = nil
+			return
+		}
+	}
+	s.Filter = &f
+}
+
+// FormatStop is the low end, midpoint or high end of a colour scale or data
+// bar. Type is min, max, num, percent or percentile; Value goes with the
+// last three.
+type FormatStop struct {
+	Type  string
+	Value string
+	Color string
+}
+
+// EditKind identifies a structural change to a sheet
+type EditKind int
+
+const (
+	EditInsertRows EditKind = iota
+	EditDeleteRows
+	EditInsertCols
+	EditDeleteCols
+	EditCopyRow
+	EditPermuteRows
+)
+
+// StructuralEdit records a row or column change so it can be replayed on
+// the original file when saving. Indexes are 0-based.
+type StructuralEdit struct {
+	Kind  EditKind
+	Index int   // First row or column affected, or the source row for EditCopyRow
+	Count int   // Number of rows or columns
+	To    int   // Destination row for EditCopyRow
+	Order []int // EditPermuteRows: offset from Index of the row that ends up at each offset
+}
+
+// CellAt returns the cell at row, col or an empty cell if it does not exist
+func (s *Sheet) CellAt(row, col int) Cell {
+	if row >= 0 && row < len(s.Rows) && col >= 0 && col < len(s.Rows[row]) {
+		return s.Rows[row][col]
+	}
+	return Cell{Row: row, Col: col}
+}
+
+// EnsureCell grows the sheet so that row, col exists and returns a pointer to it
+func (s *Sheet) EnsureCell(row, col int) *Cell {
+	for len(s.Rows) <= row {
+		s.Rows = append(s.Rows, nil)
+	}
+	for c := len(s.Rows[row]); c <= col; c++ {
+		s.Rows[row] = append(s.Rows[row], Cell{Row: row, Col: c})
+	}
+	if row+1 > s.MaxRows {
+		s.MaxRows = row + 1
+	}
+	if col+1 > s.MaxCols {
+		s.MaxCols = col + 1
+	}
+	return &s.Rows[row][col]
+}
+
+// Truncate shrinks the sheet to at most maxRows rows and maxCols columns
+func (s *Sheet) Truncate(maxRows, maxCols int) {
+	if len(s.Rows) > maxRows {
+		s.Rows = s.Rows[:maxRows]
+	}
+	for r, row := range s.Rows {
+		if len(row) > maxCols {
+			s.Rows[r] = row[:maxCols]
+		}
+	}
+	if s.MaxRows > maxRows {
+		s.MaxRows = maxRows
+	}
+	if s.MaxCols > maxCols {
+		s.MaxCols = maxCols
+	}
+}
+
+// InsertRows inserts n empty rows before row at
+func (s *Sheet) InsertRows(at, n int) {
+	for len(s.Rows) < at {
+		s.Rows = append(s.Rows, nil)
+	}
+	blank := make([][]Cell, n)
+	s.Rows = append(s.Rows[:at], append(blank, s.Rows[at:]...)...)
+	s.MaxRows += n
+	s.shiftComputed(at, n)
+	s.shiftFormats(true, at, n)
+	s.shiftFilter(true, at, n)
+	s.Renumber()
+}
+
+// DeleteRows removes n rows starting at row at and returns them
+func (s *Sheet) DeleteRows(at, n int) [][]Cell {
+	removed := make([][]Cell, n)
+	if at < len(s.Rows) {
+		end := at + n
+		if end > len(s.Rows) {
+			end = len(s.Rows)
+		}
+		copy(removed, s.Rows[at:end])
+		s.Rows = append(s.Rows[:at], s.Rows[end:]...)
+	}
+	s.MaxRows -= n
+	if s.MaxRows < 0 {
+		s.MaxRows = 0
+	}
+	s.shiftComputed(at, -n)
+	s.shiftFormats(true, at, -n)
+	s.shiftFilter(true, at, -n)
+	s.Renumber()
+	return removed
+}
+
+// RestoreRows inserts previously removed rows before row at
+func (s *Sheet) RestoreRows(at int, rows [][]Cell) {
+	s.InsertRows(at, len(rows))
+	for i, row := range rows {
+		s.Rows[at+i] = row
+	}
+	s.Renumber()
+}
+
+// InsertCols inserts n empty columns before column at
+func (s *Sheet) InsertCols(at, n int) {
+	for r, row := range s.Rows {
+		if at >= len(row) {
+			continue
+		}
+		blank := make([]Cell, n)
+		s.Rows[r] = append(row[:at], append(blank, row[at:]...)...)
+	}
+	for i := range s.Computed {
+		if s.Computed[i].Col >= at {
+			s.Computed[i].Col += n
+		}
+	}
+	s.MaxCols += n
+	s.shiftFormats(false, at, n)
+	s.shiftFilter(false, at, n)
+	s.Renumber()
+}
+
+// DeleteCols removes n columns starting at column at and returns the
+// removed cells of each row
+func (s *Sheet) DeleteCols(at, n int) [][]Cell {
+	removed := make([][]Cell, len(s.Rows))
+	for r, row := range s.Rows {
+		if at >= len(row) {
+			continue
+		}
+		end := at + n
+		if end > len(row) {
+			end = len(row)
+		}
+		removed[r] = append([]Cell(nil), row[at:end]...)
+		s.Rows[r] = append(row[:at], row[end:]...)
+	}
+	s.MaxCols -= n
+	if s.MaxCols < 0 {
+		s.MaxCols = 0
+	}
+	kept := s.Computed[:0]
+	for _, c := range s.Computed {
+		switch {
+		case c.Col >= at+n:
+			c.Col -= n
+		case c.Col >= at:
+			continue
+		}
+		kept = append(kept, c)
+	}
+	s.Computed = kept
+	s.shiftFormats(false, at, -n)
+	s.shiftFilter(false, at, -n)
+	s.Renumber()
+	return removed
+}
+
+// RestoreCols inserts previously removed columns before column at
+func (s *Sheet) RestoreCols(at, n int, cols [][]Cell) {
+	s.InsertCols(at, n)
+	for r, cells := range cols {
+		if len(cells) == 0 {
+			continue
+		}
+		for i, cell := range cells {
+			*s.EnsureCell(r, at+i) = cell
+		}
+	}
+	s.Renumber()
+}
+
+// SwapRows exchanges two rows
+func (s *Sheet) SwapRows(a, b int) {
+	for len(s.Rows) <= a || len(s.Rows) <= b {
+		s.Rows = append(s.Rows, nil)
+	}
+	s.Rows[a], s.Rows[b] = s.Rows[b], s.Rows[a]
+	s.Renumber()
+}
+
+// Renumber updates the Row and Col fields of every cell to match its position
+func (s *Sheet) Renumber() {
+	for r, row := range s.Rows {
+		for c := range row {
+			row[c].Row = r
+			row[c].Col = c
+		}
+	}
 }
 
 // Mode represents the current application mode
@@ -28,6 +468,24 @@ const (
 	ModeTheme
 	ModeChart
 	ModeSelectRange
+	ModeEdit
+	ModeConfirmQuit
+	ModeHistory
+	ModePaste
+	ModeCopyAs
+	ModeReplace
+	ModeSearchResults
+	ModeFinder
+	ModeSort
+	ModeFilter
+	ModeStats
+	ModePivot
+	ModeQuery
+	ModeComputed
+	ModeDuplicates
+	ModeProfile
+	ModeOutliers
+	ModeFormats
 )
 
 // StatusMsg represents a status message with type
@@ -42,4 +500,4 @@ const (
 	StatusSuccess = "success"
 	StatusError   = "error"
 	StatusWarning = "warning"
-)
\ No newline at end of file
+)
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShiftFormats(t *testing.T) {
+	rowRange := func(first, last int) CellRange {
+		return CellRange{FirstRow: first, FirstCol: 0, LastRow: last, LastCol: 2}
+	}
+	colRange := func(first, last int) CellRange {
+		return CellRange{FirstRow: 0, FirstCol: first, LastRow: 9, LastCol: last}
+	}
+	tests := []struct {
+		name   string
+		rows   bool
+		at, n  int
+		ranges []CellRange
+		want   []CellRange // nil when the rule is dropped
+	}{
+		{"insert above", true, 1, 2, []CellRange{rowRange(3, 5)}, []CellRange{rowRange(5, 7)}},
+		{"insert inside grows", true, 4, 2, []CellRange{rowRange(3, 5)}, []CellRange{rowRange(3, 7)}},
+		{"insert below", true, 6, 2, []CellRange{rowRange(3, 5)}, []CellRange{rowRange(3, 5)}},
+		{"open range stays open", true, 0, 1, []CellRange{rowRange(1, -1)}, []CellRange{rowRange(2, -1)}},
+		{"delete above", true, 0, -2, []CellRange{rowRange(3, 5)}, []CellRange{rowRange(1, 3)}},
+		{"delete inside shrinks", true, 4, -1, []CellRange{rowRange(3, 5)}, []CellRange{rowRange(3, 4)}},
+		{"delete over the start", true, 2, -2, []CellRange{rowRange(3, 5)}, []CellRange{rowRange(2, 3)}},
+		{"delete over the end", true, 5, -3, []CellRange{rowRange(3, 5)}, []CellRange{rowRange(3, 4)}},
+		{"delete the whole range", true, 3, -3, []CellRange{rowRange(3, 5)}, nil},
+		{"delete one of two ranges", true, 3, -3, []CellRange{rowRange(3, 5), rowRange(8, 9)}, []CellRange{rowRange(5, 6)}},
+		{"delete rows of an open range", true, 1, -5, []CellRange{rowRange(2, -1)}, []CellRange{rowRange(1, -1)}},
+		{"insert columns", false, 0, 1, []CellRange{colRange(1, 2)}, []CellRange{colRange(2, 3)}},
+		{"delete the whole column range", false, 1, -2, []CellRange{colRange(1, 2)}, nil},
+	}
+	for _, tt := range tests {
+		s := &Sheet{Formats: []FormatRule{{Kind: FormatCell, Ranges: tt.ranges, Rule: tt.name}}}
+		before := s.Formats
+		s.shiftFormats(tt.rows, tt.at, tt.n)
+
+		var got []CellRange
+		if len(s.Formats) > 0 {
+			got = s.Formats[0].Ranges
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ranges = %v, want %v", tt.name, got, tt.want)
+		}
+		if !reflect.DeepEqual(before[0].Ranges, tt.ranges) {
+			t.Errorf("%s: the earlier formats changed to %v", tt.name, before[0].Ranges)
+		}
+	}
+}